@@ -0,0 +1,110 @@
+package ravan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	announceStep = 10               // percent between two announcements
+	announceGap  = 5 * time.Second  // minimum time between two step announcements
+	announceIdle = 30 * time.Second // slow progress is announced at least this often
+)
+
+// accessibleRenderer writes short announcements for screen readers instead
+// of redrawing a bar, e.g. "45 percent, about 2 minutes remaining".
+// Every announcement is a plain line without carriage returns or escape codes.
+type accessibleRenderer struct {
+	r        *Ravan
+	lastAt   time.Time // time of the last announcement
+	lastText string
+	lastStep int    // announceStep of the last announcement
+	label    string // label of the last announcement
+	attempt  int    // retry attempt that was announced
+}
+
+func (a *accessibleRenderer) Start(s Snapshot) {}
+
+func (a *accessibleRenderer) Progress(s Snapshot) {
+	l := a.r.locale
+	now := a.r.now()
+
+	// Announce a retry once instead of its countdown
+	if s.Retry.Attempt > 0 {
+		if s.Retry.Attempt != a.attempt {
+			a.attempt = s.Retry.Attempt
+			a.say(l.retryNote(RetryInfo{Attempt: s.Retry.Attempt, Max: s.Retry.Max}), now)
+		}
+		return
+	}
+	a.attempt = 0
+
+	step := int(s.Fraction*100) / announceStep
+	since := now.Sub(a.lastAt)
+	switch {
+	case a.lastAt.IsZero(), s.Label != a.label, s.Fraction >= 1:
+	case step != a.lastStep && since >= announceGap:
+	case since >= announceIdle:
+	default:
+		return
+	}
+
+	text := a.announcement(s)
+	if text == a.lastText {
+		return
+	}
+	a.lastStep = step
+	a.label = s.Label
+	a.say(text, now)
+}
+
+func (a *accessibleRenderer) Message(s Snapshot, kind MessageKind, err error, text string) {
+	fmt.Fprintln(a.r.stdout(), a.r.locale.message(s, kind, err, text))
+}
+
+func (a *accessibleRenderer) Finish(s Snapshot) {}
+
+// say writes a single announcement.
+func (a *accessibleRenderer) say(text string, now time.Time) {
+	fmt.Fprintln(a.r.stdout(), text)
+	a.lastAt = now
+	a.lastText = text
+}
+
+// announcement returns the progress of s as it is read aloud,
+// e.g. "copy: 45 percent, about 2 minutes remaining".
+func (a *accessibleRenderer) announcement(s Snapshot) string {
+	l := a.r.locale
+	c := l.catalog()
+
+	var parts []string
+	if s.Total > 0 || s.Fraction > 0 {
+		parts = append(parts, fmt.Sprintf(c.Spoken, l.FormatInt(int64(s.Fraction*100))))
+		if s.ETA > 0 && s.Fraction < 1 {
+			parts = append(parts, fmt.Sprintf(c.Remaining, l.spokenDuration(s.ETA)))
+		}
+	} else {
+		parts = append(parts, fmt.Sprintf(c.Done, l.FormatInt(s.Current)))
+	}
+
+	text := strings.Join(parts, c.ListSep)
+	if s.Label != "" {
+		text = s.Label + ": " + text
+	}
+	return text
+}
+
+// spokenDuration rounds d to a single unit that is easy to listen to,
+// e.g. "40 seconds", "2 minutes" or "3 hours".
+func (l Locale) spokenDuration(d time.Duration) string {
+	c := l.catalog()
+	switch {
+	case d < time.Minute:
+		return l.plural(max(int64(d.Round(time.Second)/time.Second), 1), c.SpokenSeconds)
+	case d < 90*time.Minute:
+		return l.plural(int64(d.Round(time.Minute)/time.Minute), c.SpokenMinutes)
+	default:
+		return l.plural(int64(d.Round(time.Hour)/time.Hour), c.SpokenHours)
+	}
+}
@@ -0,0 +1,98 @@
+package ravan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// TestAccessibleOutput verifies announcements are plain lines written at
+// meaningful intervals instead of redraws.
+func TestAccessibleOutput(t *testing.T) {
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	r, err := New(WithOutput(AccessibleOutput), WithWriter(&buf), WithClock(clock), WithTotal(100))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	r.Add(1) // the first update is announced
+	clock.Advance(2 * time.Second)
+	r.Add(10) // a new step, but too soon after the last announcement
+	clock.Advance(4 * time.Second)
+	r.Add(10)
+	r.Add(1) // same step
+	r.Retry(2, 5, 3*time.Second)
+	clock.Advance(time.Second)
+	r.Draw(0.22) // countdown updates are not announced
+	r.WarnMsg("slow mirror")
+	clock.Advance(40 * time.Second)
+	r.Add(1) // idle for a while
+	clock.Advance(time.Second)
+	r.SetLabel("verify") // drawn once a frame is due like other updates
+	r.Add(77)
+	r.Finish(nil)
+
+	want := strings.Join([]string{
+		"1 percent",
+		"21 percent, about 23 seconds remaining",
+		"retrying 2/5",
+		"Warning: slow mirror",
+		"23 percent, about 3 minutes remaining",
+		"verify: 23 percent, about 3 minutes remaining",
+		"verify: 100 percent",
+		"Success: Operation successful (1 warning, 1 retry)",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAccessibleOutputUnknownTotal(t *testing.T) {
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	r, _ := New(WithOutput(AccessibleOutput), WithWriter(&buf), WithClock(clock), WithLanguage("de"))
+
+	r.Retry(1, 0, 0)
+	clock.Advance(time.Minute)
+	r.Add(1500)
+	r.Finish(nil)
+
+	want := "Wiederholung 1\n1.500 erledigt\nErfolg: Vorgang erfolgreich (1 Wiederholung)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q; want %q", got, want)
+	}
+}
+
+func TestAccessibleOutputFromEnv(t *testing.T) {
+	t.Setenv("RAVAN_OUTPUT", "accessible")
+
+	r, _ := New()
+	if _, ok := r.out.(*accessibleRenderer); !ok {
+		t.Errorf("expected accessible renderer, got %T", r.out)
+	}
+}
+
+func TestSpokenDuration(t *testing.T) {
+	tests := []struct {
+		l    Locale
+		d    time.Duration
+		want string
+	}{
+		{DefaultLocale, 200 * time.Millisecond, "1 second"},
+		{DefaultLocale, 40 * time.Second, "40 seconds"},
+		{DefaultLocale, 89 * time.Second, "1 minute"},
+		{DefaultLocale, 100 * time.Minute, "2 hours"},
+		{PersianLocale, 3 * time.Minute, "۳ دقیقه"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.l.spokenDuration(tt.d); got != tt.want {
+			t.Errorf("spokenDuration(%v) = %q; want %q", tt.d, got, tt.want)
+		}
+	}
+}
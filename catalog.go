@@ -0,0 +1,314 @@
+package ravan
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Catalog holds the built-in strings of a language. Format strings take the
+// already localized numbers as %s, so translations can reorder them.
+// Empty fields fall back to English, so a Catalog may translate only
+// some of the strings.
+type Catalog struct {
+	Messages Message // default texts of FailMsg, SuccessMsg, WarnMsg and InfoMsg
+
+	ErrorPrefix   string // e.g. "Error: "
+	SuccessPrefix string // e.g. "Success: "
+	WarnPrefix    string // e.g. "Warning: "
+	InfoPrefix    string // e.g. "Info: "
+
+	Statuses [4]string // words for Running, Succeeded, Failed and Aborted
+
+	Warning  [2]string // one and many, e.g. "%s warning", "%s warnings"
+	Retry    [2]string // one and many, e.g. "%s retry", "%s retries"
+	Requeued string    // e.g. "%s requeued"
+	Retrying string    // e.g. "retrying %s" with "2/5" or "2"
+	RetryIn  string    // e.g. "%s in %s" with the retry note and the wait
+
+	Hours   string // e.g. "%sh"
+	Minutes string // e.g. "%sm"
+	Seconds string // e.g. "%ss"
+	UnitSep string // between the parts of a duration, "" for "1m30s"
+	ListSep string // between the parts of the summary, e.g. ", "
+	ETA     string // remaining time shown by WithStats, e.g. "ETA %s"
+
+	// Announcements of AccessibleOutput, written to be read aloud
+	Spoken        string    // e.g. "%s percent"
+	Remaining     string    // e.g. "about %s remaining"
+	Done          string    // e.g. "%s done" when the total is unknown
+	SpokenHours   [2]string // one and many, e.g. "%s hour", "%s hours"
+	SpokenMinutes [2]string // e.g. "%s minute", "%s minutes"
+	SpokenSeconds [2]string // e.g. "%s second", "%s seconds"
+}
+
+var englishCatalog = Catalog{
+	Messages: Message{
+		Failed:  "Operation failed",
+		Success: "Operation successful",
+		Warn:    "Operation completed with warnings",
+		Info:    "Operation in progress",
+	},
+	ErrorPrefix:   "Error: ",
+	SuccessPrefix: "Success: ",
+	WarnPrefix:    "Warning: ",
+	InfoPrefix:    "Info: ",
+	Statuses:      [4]string{"running", "succeeded", "failed", "aborted"},
+	Warning:       [2]string{"%s warning", "%s warnings"},
+	Retry:         [2]string{"%s retry", "%s retries"},
+	Requeued:      "%s requeued",
+	Retrying:      "retrying %s",
+	RetryIn:       "%s in %s",
+	Hours:         "%sh",
+	Minutes:       "%sm",
+	Seconds:       "%ss",
+	ListSep:       ", ",
+	ETA:           "ETA %s",
+	Spoken:        "%s percent",
+	Remaining:     "about %s remaining",
+	Done:          "%s done",
+	SpokenHours:   [2]string{"%s hour", "%s hours"},
+	SpokenMinutes: [2]string{"%s minute", "%s minutes"},
+	SpokenSeconds: [2]string{"%s second", "%s seconds"},
+}
+
+var persianCatalog = Catalog{
+	Messages: Message{
+		Failed:  "عملیات ناموفق بود",
+		Success: "عملیات با موفقیت انجام شد",
+		Warn:    "عملیات با هشدار به پایان رسید",
+		Info:    "عملیات در حال انجام است",
+	},
+	ErrorPrefix:   "خطا: ",
+	SuccessPrefix: "موفق: ",
+	WarnPrefix:    "هشدار: ",
+	InfoPrefix:    "اطلاع: ",
+	Statuses:      [4]string{"در حال اجرا", "موفق", "ناموفق", "لغو شده"},
+	Warning:       [2]string{"%s هشدار", "%s هشدار"},
+	Retry:         [2]string{"%s تلاش دوباره", "%s تلاش دوباره"},
+	Requeued:      "%s بازگشت به صف",
+	Retrying:      "تلاش دوباره %s",
+	RetryIn:       "%s تا %s دیگر",
+	Hours:         "%s ساعت",
+	Minutes:       "%s دقیقه",
+	Seconds:       "%s ثانیه",
+	UnitSep:       " و ",
+	ListSep:       "، ",
+	ETA:           "%s مانده",
+	Spoken:        "%s درصد",
+	Remaining:     "حدود %s باقی مانده",
+	Done:          "%s انجام شد",
+	SpokenHours:   [2]string{"%s ساعت", "%s ساعت"},
+	SpokenMinutes: [2]string{"%s دقیقه", "%s دقیقه"},
+	SpokenSeconds: [2]string{"%s ثانیه", "%s ثانیه"},
+}
+
+var arabicCatalog = Catalog{
+	Messages: Message{
+		Failed:  "فشلت العملية",
+		Success: "تمت العملية بنجاح",
+		Warn:    "اكتملت العملية مع تحذيرات",
+		Info:    "العملية قيد التنفيذ",
+	},
+	ErrorPrefix:   "خطأ: ",
+	SuccessPrefix: "نجاح: ",
+	WarnPrefix:    "تحذير: ",
+	InfoPrefix:    "معلومة: ",
+	Statuses:      [4]string{"قيد التشغيل", "نجحت", "فشلت", "أُلغيت"},
+	Warning:       [2]string{"%s تحذير", "%s تحذيرات"},
+	Retry:         [2]string{"%s إعادة محاولة", "%s إعادات محاولة"},
+	Requeued:      "%s أُعيد إلى الطابور",
+	Retrying:      "إعادة المحاولة %s",
+	RetryIn:       "%s بعد %s",
+	Hours:         "%s ساعة",
+	Minutes:       "%s دقيقة",
+	Seconds:       "%s ثانية",
+	UnitSep:       " و",
+	ListSep:       "، ",
+	ETA:           "متبقٍ %s",
+	Spoken:        "%s بالمئة",
+	Remaining:     "يتبقى حوالي %s",
+	Done:          "تم %s",
+	SpokenHours:   [2]string{"%s ساعة", "%s ساعات"},
+	SpokenMinutes: [2]string{"%s دقيقة", "%s دقائق"},
+	SpokenSeconds: [2]string{"%s ثانية", "%s ثوانٍ"},
+}
+
+var germanCatalog = Catalog{
+	Messages: Message{
+		Failed:  "Vorgang fehlgeschlagen",
+		Success: "Vorgang erfolgreich",
+		Warn:    "Vorgang mit Warnungen abgeschlossen",
+		Info:    "Vorgang läuft",
+	},
+	ErrorPrefix:   "Fehler: ",
+	SuccessPrefix: "Erfolg: ",
+	WarnPrefix:    "Warnung: ",
+	InfoPrefix:    "Info: ",
+	Statuses:      [4]string{"läuft", "erfolgreich", "fehlgeschlagen", "abgebrochen"},
+	Warning:       [2]string{"%s Warnung", "%s Warnungen"},
+	Retry:         [2]string{"%s Wiederholung", "%s Wiederholungen"},
+	Requeued:      "%s erneut eingereiht",
+	Retrying:      "Wiederholung %s",
+	RetryIn:       "%s in %s",
+	Hours:         "%s h",
+	Minutes:       "%s min",
+	Seconds:       "%s s",
+	UnitSep:       " ",
+	ListSep:       ", ",
+	ETA:           "noch %s",
+	Spoken:        "%s Prozent",
+	Remaining:     "noch etwa %s",
+	Done:          "%s erledigt",
+	SpokenHours:   [2]string{"%s Stunde", "%s Stunden"},
+	SpokenMinutes: [2]string{"%s Minute", "%s Minuten"},
+	SpokenSeconds: [2]string{"%s Sekunde", "%s Sekunden"},
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		"en": EnglishLocale,
+		"fa": PersianLocale,
+		"ar": ArabicLocale,
+		"de": GermanLocale,
+	}
+)
+
+// RegisterLocale makes l available to WithLanguage and the LANG environment
+// variable under a language tag such as "fr" or "pt-BR". The Catalog of l
+// may be partly filled; the strings it leaves empty are English.
+func RegisterLocale(tag string, l Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[normalizeTag(tag)] = l
+}
+
+// LocaleFor returns the locale of a language tag. Tags are matched like
+// "pt-BR", "pt_BR.UTF-8" and then "pt", so a region falls back to its language.
+func LocaleFor(tag string) (Locale, bool) {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return Locale{}, false
+	}
+
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	if l, ok := locales[tag]; ok {
+		return l, true
+	}
+	if lang, _, found := strings.Cut(tag, "-"); found {
+		l, ok := locales[lang]
+		return l, ok
+	}
+	return Locale{}, false
+}
+
+// WithLanguage selects the locale registered for a language tag,
+// e.g. "fa" or "de-AT". It overrides the LANG environment variable.
+func WithLanguage(tag string) Option {
+	return func(r *Ravan) error {
+		l, ok := LocaleFor(tag)
+		if !ok {
+			return fmt.Errorf("unknown language %q", tag)
+		}
+		r.locale = l
+		return nil
+	}
+}
+
+// envLocale returns the locale for the first of LC_ALL, LC_MESSAGES and LANG
+// that is set, like the C library. "C", "POSIX" and unknown languages are ignored.
+func envLocale() (Locale, bool) {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return LocaleFor(v)
+		}
+	}
+	return Locale{}, false
+}
+
+// normalizeTag turns POSIX locale names like "fa_IR.UTF-8@calendar" into "fa-ir".
+func normalizeTag(tag string) string {
+	tag, _, _ = strings.Cut(tag, ".")
+	tag, _, _ = strings.Cut(tag, "@")
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "c" || tag == "posix" {
+		return ""
+	}
+	return tag
+}
+
+// catalog returns the strings of the locale, English where it has none.
+func (l Locale) catalog() Catalog {
+	return l.Catalog.withDefaults(englishCatalog)
+}
+
+// withDefaults fills the empty strings of c with the strings of def.
+// UnitSep is left as is, an empty separator is a valid choice.
+func (c Catalog) withDefaults(def Catalog) Catalog {
+	c.Messages = c.Messages.withDefaults(def.Messages)
+	for _, f := range []struct{ s, def *string }{
+		{&c.ErrorPrefix, &def.ErrorPrefix},
+		{&c.SuccessPrefix, &def.SuccessPrefix},
+		{&c.WarnPrefix, &def.WarnPrefix},
+		{&c.InfoPrefix, &def.InfoPrefix},
+		{&c.Requeued, &def.Requeued},
+		{&c.Retrying, &def.Retrying},
+		{&c.RetryIn, &def.RetryIn},
+		{&c.Hours, &def.Hours},
+		{&c.Minutes, &def.Minutes},
+		{&c.Seconds, &def.Seconds},
+		{&c.ListSep, &def.ListSep},
+		{&c.ETA, &def.ETA},
+		{&c.Spoken, &def.Spoken},
+		{&c.Remaining, &def.Remaining},
+		{&c.Done, &def.Done},
+	} {
+		if *f.s == "" {
+			*f.s = *f.def
+		}
+	}
+	fillStrings(c.Statuses[:], def.Statuses[:])
+	fillStrings(c.Warning[:], def.Warning[:])
+	fillStrings(c.Retry[:], def.Retry[:])
+	fillStrings(c.SpokenHours[:], def.SpokenHours[:])
+	fillStrings(c.SpokenMinutes[:], def.SpokenMinutes[:])
+	fillStrings(c.SpokenSeconds[:], def.SpokenSeconds[:])
+	return c
+}
+
+// fillStrings sets the empty strings of s to the strings of def.
+func fillStrings(s, def []string) {
+	for i := range s {
+		if s[i] == "" {
+			s[i] = def[i]
+		}
+	}
+}
+
+// StatusText returns the localized word for a status, e.g. "succeeded".
+func (l Locale) StatusText(s Status) string {
+	if s < Running || s > Aborted {
+		return s.String()
+	}
+	return l.catalog().Statuses[s]
+}
+
+// withDefaults fills the empty texts of m with the texts of def.
+func (m Message) withDefaults(def Message) Message {
+	if m.Failed == "" {
+		m.Failed = def.Failed
+	}
+	if m.Success == "" {
+		m.Success = def.Success
+	}
+	if m.Warn == "" {
+		m.Warn = def.Warn
+	}
+	if m.Info == "" {
+		m.Info = def.Info
+	}
+	return m
+}
@@ -0,0 +1,190 @@
+package ravan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+func TestLocaleFor(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Locale
+		ok   bool
+	}{
+		{"fa", PersianLocale, true},
+		{"fa_IR.UTF-8", PersianLocale, true},
+		{"de-AT", GermanLocale, true},
+		{"en_US.UTF-8@euro", EnglishLocale, true},
+		{"AR", ArabicLocale, true},
+		{"C", Locale{}, false},
+		{"POSIX", Locale{}, false},
+		{"xx-YY", Locale{}, false},
+		{"", Locale{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := LocaleFor(tt.tag)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("LocaleFor(%q) = %+v, %v; want %+v, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	french := Locale{Thousands: " ", Decimal: ",", Catalog: englishCatalog}
+	french.Catalog.Messages.Success = "Opération réussie"
+	RegisterLocale("fr", french)
+
+	r, err := New(WithLanguage("fr_CA"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if r.message.Success != "Opération réussie" {
+		t.Errorf("Success = %q; want the registered catalog text", r.message.Success)
+	}
+}
+
+// TestPartialCatalog verifies the strings a catalog leaves empty are English.
+func TestPartialCatalog(t *testing.T) {
+	RegisterLocale("xx-partial", Locale{Catalog: Catalog{
+		Messages:      Message{Success: "Fertig"},
+		SuccessPrefix: "OK: ",
+	}})
+	term := ravantest.NewTerminal(60)
+	r, _ := New(WithWriter(term), WithLanguage("xx-partial"), WithWidth(10), WithTotal(3), WithTheme(Theme{}))
+
+	r.Add(1)
+	r.Retry(1, 3, 90*time.Second)
+	if got, want := term.String(), "[===       ] 33% retrying 1/3 in 1m30s"; got != want {
+		t.Errorf("screen = %q; want %q", got, want)
+	}
+	r.Add(2)
+	r.Finish(nil)
+	if got := term.Lines()[1]; got != "OK: Fertig (1 retry)" {
+		t.Errorf("message = %q; want the catalog text with English statistics", got)
+	}
+}
+
+func TestWithLanguageUnknown(t *testing.T) {
+	if _, err := New(WithLanguage("xx")); err == nil {
+		t.Error("expected error for unknown language")
+	}
+}
+
+// TestLanguageFromEnv verifies LC_ALL wins over LANG and options win over both.
+func TestLanguageFromEnv(t *testing.T) {
+	t.Setenv("LANG", "fa_IR.UTF-8")
+
+	r, _ := New()
+	if r.locale != PersianLocale || r.message.Failed != persianCatalog.Messages.Failed {
+		t.Errorf("LANG=fa_IR: locale %+v, message %q", r.locale, r.message.Failed)
+	}
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	r, _ = New()
+	if r.locale != GermanLocale {
+		t.Errorf("expected LC_ALL to win over LANG, got %+v", r.locale)
+	}
+
+	r, _ = New(WithLanguage("en"), WithMessage(&Message{Success: "done"}))
+	if r.locale != EnglishLocale || r.message.Failed != "Operation failed" || r.message.Success != "done" {
+		t.Errorf("expected options to win, got %+v %+v", r.locale, r.message)
+	}
+
+	t.Setenv("LC_ALL", "C")
+	if r, _ = New(); r.locale != DefaultLocale {
+		t.Errorf("LC_ALL=C: expected the default locale, got %+v", r.locale)
+	}
+}
+
+func TestFormatNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"default int", DefaultLocale.FormatInt(1234567), "1234567"},
+		{"english int", EnglishLocale.FormatInt(1234567), "1,234,567"},
+		{"english negative", EnglishLocale.FormatInt(-1234), "-1,234"},
+		{"english small", EnglishLocale.FormatInt(999), "999"},
+		{"english float", EnglishLocale.FormatFloat(1234.5, 2), "1,234.50"},
+		{"german float", GermanLocale.FormatFloat(1234.5, 1), "1.234,5"},
+		{"german whole", GermanLocale.FormatFloat(1234.5, 0), "1.234"},
+		{"persian int", PersianLocale.FormatInt(1234), "۱٬۲۳۴"},
+		{"arabic float", ArabicLocale.FormatFloat(12.5, 1), "١٢٫٥"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q; want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		l    Locale
+		d    time.Duration
+		want string
+	}{
+		{DefaultLocale, 0, "0s"},
+		{DefaultLocale, 3 * time.Second, "3s"},
+		{DefaultLocale, 90*time.Second + 400*time.Millisecond, "1m30s"},
+		{DefaultLocale, time.Hour + time.Second, "1h1s"},
+		{GermanLocale, 90 * time.Second, "1 min 30 s"},
+		{PersianLocale, 125 * time.Second, "۲ دقیقه و ۵ ثانیه"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.l.FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q; want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestStatusText(t *testing.T) {
+	if got := DefaultLocale.StatusText(Succeeded); got != "succeeded" {
+		t.Errorf("StatusText(Succeeded) = %q", got)
+	}
+	if got := GermanLocale.StatusText(Aborted); got != "abgebrochen" {
+		t.Errorf("StatusText(Aborted) = %q", got)
+	}
+	if got := GermanLocale.StatusText(Status(9)); got != "Status(9)" {
+		t.Errorf("StatusText(9) = %q", got)
+	}
+}
+
+// TestGermanMessages verifies prefixes, default messages, the retry note and
+// the summary come from the catalog, while snapshots keep the English note.
+func TestGermanMessages(t *testing.T) {
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	term := ravantest.NewTerminal(100)
+	r, _ := New(WithWriter(term), WithClock(clock), WithLanguage("de"), WithWidth(20),
+		WithTotal(2000), WithTheme(Theme{}))
+
+	r.Add(1000)
+	r.Retry(2, 3, 90*time.Second)
+	if got, want := term.String(), "[==========          ] 50% Wiederholung 2/3 in 1 min 30 s"; got != want {
+		t.Errorf("screen = %q; want %q", got, want)
+	}
+	if got, want := r.Snapshot().Note, "retrying 2/3 in 1m30s"; got != want {
+		t.Errorf("Snapshot().Note = %q; want %q", got, want)
+	}
+
+	r.WarnMsg()
+	r.Requeue(1000)
+	r.Add(2000)
+	r.Finish(nil)
+
+	lines := strings.Split(term.String(), "\n")
+	want := []string{
+		"Warnung: Vorgang mit Warnungen abgeschlossen",
+		"Erfolg: Vorgang erfolgreich (1 Warnung, 1 Wiederholung, 1.000 erneut eingereiht)",
+	}
+	if got := []string{lines[1], lines[len(lines)-1]}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("messages =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
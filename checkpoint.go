@@ -0,0 +1,172 @@
+package ravan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultCheckpointInterval is how often Resume saves unless WithCheckpoint says otherwise.
+const defaultCheckpointInterval = 5 * time.Second
+
+// checkpointVersion is written to checkpoint files so the format can change.
+const checkpointVersion = 1
+
+// checkpointer saves the state of a bar, see WithCheckpoint.
+type checkpointer struct {
+	path     string
+	interval time.Duration
+	saved    time.Time // time of the last save
+}
+
+// checkpoint is the content of a checkpoint file. Elapsed is in seconds.
+type checkpoint struct {
+	Version  int       `json:"version"`
+	Current  int64     `json:"current"`
+	Total    int64     `json:"total"`
+	Fraction float64   `json:"fraction"`
+	Elapsed  float64   `json:"elapsed"`
+	Label    string    `json:"label,omitempty"`
+	Warnings int       `json:"warnings"`
+	Retries  int       `json:"retries"`
+	Requeued int64     `json:"requeued"`
+	Status   string    `json:"status"`
+	Saved    time.Time `json:"saved"`
+}
+
+// WithCheckpoint saves the count, total, fraction, elapsed time and label
+// of the bar to a JSON file at path, at most once per interval while it
+// is counted or drawn, and when it fails or is aborted. The file is replaced
+// atomically, so a crash never leaves a torn checkpoint behind, and it
+// is removed when the bar succeeds. Failed saves are ignored like failed
+// terminal writes. Restore the bar with Resume.
+//
+// Without a total every Add takes the lock of the bar to see whether a
+// save is due, instead of only every 0.1% of the total.
+//
+// The count is what Resume returns as the offset to continue from, so
+// add items to the bar only once their work is committed.
+func WithCheckpoint(path string, interval time.Duration) Option {
+	return func(r *Ravan) error {
+		if path == "" {
+			return fmt.Errorf("checkpoint path must not be empty")
+		}
+		if interval < 0 {
+			return fmt.Errorf("checkpoint interval must not be negative: %s", interval)
+		}
+		r.checkpoint = &checkpointer{path: path, interval: interval}
+		return nil
+	}
+}
+
+// Resume creates a bar that continues from the checkpoint file at path,
+// with its count, elapsed time, label and the warnings, retries and
+// requeued items of the earlier run, and returns the count as the
+// offset of the first item still to do. A bar moved with Draw instead
+// of a count gets its fraction back, see Snapshot. Without a checkpoint
+// file it creates a fresh bar at offset 0.
+//
+// The bar saves to the same file every 5 seconds; pass WithCheckpoint
+// in opts to change that. opts may set the total, which must match the
+// total of the checkpoint.
+func Resume(path string, opts ...Option) (*Ravan, int64, error) {
+	r, err := New(append([]Option{WithCheckpoint(path, defaultCheckpointInterval)}, opts...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, 0, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if c.Version != checkpointVersion {
+		return nil, 0, fmt.Errorf("invalid checkpoint %s: unsupported version %d", path, c.Version)
+	}
+	if r.total > 0 && c.Total > 0 && r.total != c.Total {
+		return nil, 0, fmt.Errorf("checkpoint %s is for a total of %d, not %d", path, c.Total, r.total)
+	}
+
+	if r.total == 0 {
+		r.total = c.Total
+	}
+	if r.label == "" {
+		r.label = c.Label
+	}
+	r.current.Store(c.Current)
+	r.warnings = c.Warnings
+	r.retries = c.Retries
+	r.requeued = c.Requeued
+	r.start = r.now().Add(-time.Duration(c.Elapsed * float64(time.Second)))
+	r.progress = min(max(c.Fraction, 0), 1) // bars drawn without a total
+	r.updateProgress()
+	r.scheduleNextDraw()
+	return r, c.Current, nil
+}
+
+// saveCheckpoint is the watcher of WithCheckpoint. r.mu must be held.
+func (r *Ravan) saveCheckpoint(s Snapshot) {
+	c := r.checkpoint
+	switch {
+	case s.Status == Succeeded:
+		os.Remove(c.path)
+		return
+	case s.Status != Running:
+		// Always keep the state a failed run ends with
+	case !c.saved.IsZero() && r.now().Sub(c.saved) < c.interval:
+		return
+	}
+
+	c.saved = r.now()
+	writeFileAtomic(c.path, checkpoint{
+		Version:  checkpointVersion,
+		Current:  s.Current,
+		Total:    s.Total,
+		Fraction: s.Fraction,
+		Elapsed:  s.Elapsed.Seconds(),
+		Label:    s.Label,
+		Warnings: s.Warnings,
+		Retries:  s.Retries,
+		Requeued: s.Requeued,
+		Status:   s.Status.String(),
+		Saved:    c.saved,
+	})
+}
+
+// writeFileAtomic writes v as JSON to a temporary file next to path and
+// renames it to path once it is synced.
+func writeFileAtomic(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package ravan
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// readCheckpoint returns the checkpoint saved at path.
+func readCheckpoint(t *testing.T, path string) checkpoint {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading checkpoint: %v", err)
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("invalid checkpoint %q: %v", data, err)
+	}
+	return c
+}
+
+// TestCheckpoint verifies the state is saved once per interval and kept
+// when the bar fails.
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.json")
+	clock := ravantest.NewClock(time.Now())
+	r, err := New(WithTotal(100), WithLabel("import"), WithWriter(io.Discard), WithClock(clock),
+		WithMaxFPS(0), WithCheckpoint(path, 10*time.Second))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	clock.Advance(time.Second)
+	r.Add(10) // the first draw saves
+	clock.Advance(5 * time.Second)
+	r.Add(20) // within the interval
+	if c := readCheckpoint(t, path); c.Current != 10 || c.Total != 100 || c.Elapsed != 1 || c.Label != "import" {
+		t.Errorf("checkpoint = %+v; want 10 of 100 after 1s", c)
+	}
+
+	clock.Advance(5 * time.Second)
+	r.WarnMsg() // messages save too
+	r.Add(40)
+	if c := readCheckpoint(t, path); c.Current != 30 || c.Elapsed != 11 || c.Warnings != 1 {
+		t.Errorf("checkpoint = %+v; want 30 after 11s with a warning", c)
+	}
+
+	r.Add(5)
+	r.Finish(errors.New("crashed"))
+	if c := readCheckpoint(t, path); c.Current != 75 || c.Status != "failed" {
+		t.Errorf("checkpoint = %+v; want the final count of the failed run", c)
+	}
+
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+// TestCheckpointWithoutTotal verifies a bar that never draws is saved too.
+func TestCheckpointWithoutTotal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json")
+	clock := ravantest.NewClock(time.Now())
+	r, _ := New(WithWriter(io.Discard), WithClock(clock), WithCheckpoint(path, time.Second))
+
+	for i := 0; i < 1000; i++ {
+		clock.Advance(10 * time.Millisecond)
+		r.Increment()
+	}
+	if c := readCheckpoint(t, path); c.Current != 901 || c.Total != 0 || c.Status != "running" {
+		t.Errorf("checkpoint = %+v; want 901 items after 9s", c)
+	}
+}
+
+// TestResume verifies a bar continues where the checkpoint left off and
+// removes it on success.
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.json")
+	clock := ravantest.NewClock(time.Now())
+	r, _ := New(WithTotal(100), WithLabel("import"), WithWriter(io.Discard), WithClock(clock), WithCheckpoint(path, 0))
+	clock.Advance(7 * time.Second)
+	r.Add(70)
+	r.Retry(2, 3, 0)
+	r.Finish(ErrAborted)
+
+	term := ravantest.NewTerminal(60)
+	r, offset, err := Resume(path, WithWriter(term), WithWidth(10), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+	if offset != 70 {
+		t.Errorf("offset = %d; want 70", offset)
+	}
+	s := r.Snapshot()
+	if s.Current != 70 || s.Total != 100 || s.Elapsed != 7*time.Second || s.Label != "import" || s.Retries != 1 {
+		t.Errorf("resumed snapshot = %+v; want 70 of 100 after 7s", s)
+	}
+
+	clock.Advance(3 * time.Second)
+	r.Add(30)
+	r.Finish(nil)
+	if got := term.String(); got != "import [==========] 100%\nSuccess: Operation successful (1 retry)" {
+		t.Errorf("screen:\n%s", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint must be removed after success, got %v", err)
+	}
+}
+
+// TestResumeDrawn verifies a bar moved with Draw resumes at its fraction.
+func TestResumeDrawn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.json")
+	r, _ := New(WithWriter(io.Discard), WithCheckpoint(path, time.Minute))
+	r.Draw(0.7)
+	r.Finish(errors.New("crashed"))
+
+	r, offset, err := Resume(path, WithWriter(io.Discard))
+	if err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+	if s := r.Snapshot(); offset != 0 || s.Fraction != 0.7 {
+		t.Errorf("Resume() = offset %d, fraction %v; want 0 and 0.7", offset, s.Fraction)
+	}
+}
+
+func TestResumeWithoutCheckpoint(t *testing.T) {
+	r, offset, err := Resume(filepath.Join(t.TempDir(), "none.json"), WithTotal(5), WithWriter(io.Discard))
+	if err != nil || offset != 0 || r.Snapshot().Current != 0 {
+		t.Errorf("Resume() = offset %d, %v; want a fresh bar", offset, err)
+	}
+}
+
+func TestResumeErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		opts    []Option
+		want    string
+	}{
+		{"syntax", "{", nil, "invalid checkpoint"},
+		{"version", `{"version": 9}`, nil, "unsupported version 9"},
+		{"total", `{"version": 1, "current": 5, "total": 10}`, []Option{WithTotal(20)}, "for a total of 10, not 20"},
+		{"interval", `{"version": 1}`, []Option{WithCheckpoint("x", -time.Second)}, "interval must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			os.WriteFile(path, []byte(tt.content), 0o644)
+			_, _, err := Resume(path, append(tt.opts, WithWriter(io.Discard))...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Resume() error = %v; want %q", err, tt.want)
+			}
+		})
+	}
+}
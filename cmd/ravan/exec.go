@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pooulad/ravan"
+)
+
+// Built-in formats tried when no pattern is given
+var (
+	percentPattern  = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*%`)
+	fractionPattern = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+)
+
+// execCmd runs a command and drives the bar from the numbers in its output.
+func execCmd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ravan exec", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	pattern := fs.String("pattern", "", "regular expression with the percent as one group or current and total as two groups (default: NN% or N/M)")
+	hide := fs.Bool("hide", false, "hide the output of the command")
+	name := fs.String("N", "", "label shown in front of the bar")
+	width := fs.Int("w", 0, "bar width (default 50)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ravan exec [flags] -- command [args...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	parser, err := newLineParser(*pattern)
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+
+	opts, err := ravan.LoadConfig("")
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+	opts = append(opts, ravan.WithWriter(stderr), ravan.WithLabel(*name))
+	if *width > 0 {
+		opts = append(opts, ravan.WithWidth(*width))
+	}
+	bar, err := ravan.New(opts...)
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Stdin = stdin
+	outPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		bar.Finish(err)
+		return 1
+	}
+	errPipe, err := cmd.StderrPipe()
+	if err != nil {
+		bar.Finish(err)
+		return 1
+	}
+	if err := cmd.Start(); err != nil {
+		bar.Finish(err)
+		return 127
+	}
+
+	// Output meant for a file or pipe goes there, everything else is
+	// printed above the bar so the two don't garble each other. Lines
+	// redrawn with \r are only shown by the bar.
+	printOut := logLine(bar)
+	if !isTerminal(stdout) {
+		printOut = func(text string) {
+			if !strings.HasSuffix(text, "\n") && !strings.HasSuffix(text, "\r") {
+				text += "\n"
+			}
+			fmt.Fprint(stdout, text)
+		}
+	}
+	if *hide {
+		printOut = func(string) {}
+	}
+	printErr := logLine(bar)
+	if *hide {
+		printErr = func(string) {}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go scanLines(outPipe, parser, bar, printOut, &wg)
+	go scanLines(errPipe, parser, bar, printErr, &wg)
+	wg.Wait()
+
+	err = cmd.Wait()
+	bar.Finish(err)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// logLine returns a function printing lines above the bar, skipping
+// the lines redrawn with \r.
+func logLine(bar *ravan.Ravan) func(text string) {
+	return func(text string) {
+		if !strings.HasSuffix(text, "\r") {
+			bar.Log(strings.TrimRight(text, "\r\n"))
+		}
+	}
+}
+
+// scanLines reads the output of the command line by line. Tools that
+// redraw their progress end a line with \r instead of \n, which is a
+// line of its own too. print gets every line with its line ending.
+func scanLines(r io.Reader, parser *lineParser, bar *ravan.Ravan, print func(text string), wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanRedrawnLines)
+	for scanner.Scan() {
+		text := scanner.Text()
+		print(text)
+		if progress, ok := parser.progress(strings.TrimRight(text, "\r\n")); ok {
+			bar.Draw(progress)
+		}
+	}
+	// Drain what the scanner can't split so the command doesn't block
+	io.Copy(io.Discard, r)
+}
+
+// scanRedrawnLines is a bufio.SplitFunc for lines ending in \n, \r\n or
+// \r. The tokens keep their line ending so redrawn lines can be told apart.
+func scanRedrawnLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	i := bytes.IndexAny(data, "\r\n")
+	switch {
+	case i < 0:
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	case data[i] == '\n':
+		return i + 1, data[:i+1], nil
+	case i+1 < len(data) && data[i+1] == '\n':
+		return i + 2, data[:i+2], nil
+	case i+1 == len(data) && !atEOF:
+		return 0, nil, nil // \r\n may be split across reads
+	default:
+		return i + 1, data[:i+1], nil
+	}
+}
+
+// lineParser finds the progress in a line of output.
+type lineParser struct {
+	patterns []*regexp.Regexp
+}
+
+// newLineParser compiles a pattern with one group for the percent or two
+// groups for the current and total count. An empty pattern uses the
+// built-in "NN%" and "N/M" formats.
+func newLineParser(pattern string) (*lineParser, error) {
+	if pattern == "" {
+		return &lineParser{patterns: []*regexp.Regexp{percentPattern, fractionPattern}}, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if n := re.NumSubexp(); n != 1 && n != 2 {
+		return nil, fmt.Errorf("pattern must have 1 or 2 groups, got %d", n)
+	}
+	return &lineParser{patterns: []*regexp.Regexp{re}}, nil
+}
+
+// progress returns the progress between 0.0 and 1.0 of the first pattern
+// that matches line, using its last match in the line.
+func (p *lineParser) progress(line string) (float64, bool) {
+	for _, re := range p.patterns {
+		matches := re.FindAllStringSubmatch(line, -1)
+		for i := len(matches) - 1; i >= 0; i-- {
+			if progress, ok := matchProgress(matches[i]); ok {
+				return progress, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// matchProgress returns the progress of a match with the percent or the
+// current and total count as groups.
+func matchProgress(m []string) (float64, bool) {
+	var progress float64
+	if len(m) == 2 {
+		percent, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		progress = percent / 100
+	} else {
+		current, err1 := strconv.ParseFloat(m[1], 64)
+		total, err2 := strconv.ParseFloat(m[2], 64)
+		if err1 != nil || err2 != nil || total <= 0 {
+			return 0, false
+		}
+		progress = current / total
+	}
+	return min(max(progress, 0), 1), true
+}
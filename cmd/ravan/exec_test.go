@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// runSh runs ravan exec with a shell script and returns the exit code,
+// stdout and the stderr screen.
+func runSh(t *testing.T, flags []string, script string) (int, string, *ravantest.Terminal) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	var stdout bytes.Buffer
+	screen := ravantest.NewTerminal(60)
+	args := append(append([]string{"exec", "-w", "10"}, flags...), "--", "sh", "-c", script)
+	code := run(args, strings.NewReader(""), &stdout, screen)
+	return code, stdout.String(), screen
+}
+
+// TestExec verifies the output is passed through while the numbers drive the bar.
+func TestExec(t *testing.T) {
+	code, stdout, screen := runSh(t, nil, "echo 1/4; echo warming up >&2; echo 50%; echo done 4/4")
+	if code != 0 {
+		t.Fatalf("exit code %d, screen:\n%s", code, screen)
+	}
+	if stdout != "1/4\n50%\ndone 4/4\n" {
+		t.Errorf("stdout = %q; want the output of the command", stdout)
+	}
+
+	// stdout and stderr are read concurrently, so only their own order is fixed
+	if got := screen.String(); !strings.Contains(got, "warming up\n") {
+		t.Errorf("expected stderr of the command on its own line, got\n%s", got)
+	}
+	if got := screen.String(); !strings.Contains(got, "[==========] 100%") || !strings.HasSuffix(got, "Success: Operation successful") {
+		t.Errorf("expected a complete bar and success, got\n%s", got)
+	}
+}
+
+// TestExecFailure verifies the exit code is passed on and shown as a failure.
+func TestExecFailure(t *testing.T) {
+	code, _, screen := runSh(t, []string{"-hide"}, "echo 30%; echo broken >&2; exit 3")
+	if code != 3 {
+		t.Errorf("exit code %d; want 3", code)
+	}
+
+	got := screen.String()
+	if strings.Contains(got, "broken") {
+		t.Errorf("expected hidden output, got\n%s", got)
+	}
+	if !strings.Contains(got, "[===       ] 30%") || !strings.HasSuffix(got, "Error: exit status 3. Operation failed") {
+		t.Errorf("expected the bar at 30%% and a failure, got\n%s", got)
+	}
+}
+
+// TestExecRedraw verifies lines redrawn with \r drive the bar one by one.
+func TestExecRedraw(t *testing.T) {
+	code, stdout, screen := runSh(t, nil, `printf '10%%\r50%%\r90%%\r\ndone\n'`)
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if stdout != "10%\r50%\r90%\r\ndone\n" {
+		t.Errorf("stdout = %q; want the output of the command", stdout)
+	}
+
+	want := "[========= ] 90%\nSuccess: Operation successful"
+	if got := screen.String(); got != want {
+		t.Errorf("screen:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestScanRedrawnLines(t *testing.T) {
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader("a\rb\r\nc\nd")))
+	scanner.Split(scanRedrawnLines)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	if got := strings.Join(tokens, "|"); got != "a\r|b\r\n|c\n|d" {
+		t.Errorf("tokens %q; want lines ending in \\r, \\r\\n and \\n", got)
+	}
+}
+
+func TestExecPattern(t *testing.T) {
+	code, _, screen := runSh(t, []string{"-pattern", `step (\d+) of (\d+)`}, "echo 'step 1 of 5 (20%)'; echo 'step 2 of 5'")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if got := screen.String(); !strings.Contains(got, "[====      ] 40%") {
+		t.Errorf("expected the custom pattern to win over NN%%, got\n%s", got)
+	}
+}
+
+func TestExecUsage(t *testing.T) {
+	for _, args := range [][]string{{"exec"}, {"exec", "-pattern", "(a)(b)(c)", "--", "true"}, {"exec", "-pattern", "(", "--", "true"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 2 {
+			t.Errorf("run(%q) = %d; want 2", args, code)
+		}
+	}
+}
+
+func TestLineParser(t *testing.T) {
+	p, _ := newLineParser("")
+	tests := []struct {
+		line string
+		want float64
+		ok   bool
+	}{
+		{"downloading 45%", 0.45, true},
+		{"12.5 % done", 0.125, true},
+		{"row 30/120", 0.25, true},
+		{"130%", 1, true},
+		{"10% 50% 90%", 0.9, true},
+		{"0/0", 0, false},
+		{"no numbers", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := p.progress(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("progress(%q) = %v, %v; want %v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
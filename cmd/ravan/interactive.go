@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pooulad/ravan"
+	"golang.org/x/term"
+)
+
+// barChar is a BarCharacter with the name of its constant for generated code.
+type barChar struct {
+	char ravan.BarCharacter
+	name string
+}
+
+var barChars = []barChar{
+	{ravan.Empty, "Empty"},
+	{ravan.Hash, "Hash"},
+	{ravan.Asterisk, "Asterisk"},
+	{ravan.Equal, "Equal"},
+	{ravan.Plus, "Plus"},
+	{ravan.Dash, "Dash"},
+	{ravan.GreaterThan, "GreaterThan"},
+	{ravan.LessThan, "LessThan"},
+	{ravan.Colon, "Colon"},
+	{ravan.Exclamation, "Exclamation"},
+	{ravan.DollarSign, "DollarSign"},
+	{ravan.AtSign, "AtSign"},
+	{ravan.Percent, "Percent"},
+	{ravan.CircumFlex, "CircumFlex"},
+	{ravan.And, "And"},
+}
+
+// palette lists the colors a theme color cycles through, "" is no color.
+var palette = []struct{ name, code string }{
+	{"green", "\033[32m"},
+	{"red", "\033[31m"},
+	{"yellow", "\033[33m"},
+	{"cyan", "\033[36m"},
+	{"blue", "\033[34m"},
+	{"magenta", "\033[35m"},
+	{"bold", "\033[1m"},
+	{"none", ""},
+}
+
+// template is a preset layout previewed with sample data.
+type template struct {
+	name string
+	code []string // extra options in generated code
+	opts []ravan.Option
+	snap ravan.Snapshot
+}
+
+var templates = []template{
+	{
+		name: "bar",
+		snap: ravan.Snapshot{Total: 100},
+	},
+	{
+		name: "label",
+		code: []string{`ravan.WithLabel("download")`},
+		opts: []ravan.Option{ravan.WithLabel("download")},
+		snap: ravan.Snapshot{Total: 100, Label: "download"},
+	},
+	{
+		name: "stats",
+		code: []string{"ravan.WithStats(ravan.Bytes)"},
+		opts: []ravan.Option{ravan.WithStats(ravan.Bytes)},
+		snap: ravan.Snapshot{Total: 4 << 30, Rate: 35 << 20},
+	},
+	{
+		name: "persian",
+		code: []string{"ravan.WithLocale(ravan.PersianLocale)"},
+		opts: []ravan.Option{ravan.WithLocale(ravan.PersianLocale)},
+		snap: ravan.Snapshot{Total: 100},
+	},
+}
+
+// designer is the state of the interactive mode.
+type designer struct {
+	width      int
+	complete   int    // index into barChars
+	incomplete int    // index into barChars
+	colors     [4]int // palette index of success, failed, warn and info, -1 for the default
+	plain      bool   // no colors at all
+	template   int    // index into templates
+}
+
+// newDesigner starts with the defaults of ravan.New.
+func newDesigner() *designer {
+	return &designer{
+		width:      50,
+		complete:   charIndex(ravan.Equal),
+		incomplete: charIndex(ravan.Empty),
+		colors:     [4]int{-1, -1, -1, -1},
+	}
+}
+
+func charIndex(c ravan.BarCharacter) int {
+	for i, bc := range barChars {
+		if bc.char == c {
+			return i
+		}
+	}
+	return 0
+}
+
+// validChars reports whether the characters are accepted by ravan.New.
+func validChars(complete, incomplete int) bool {
+	_, err := ravan.New(
+		ravan.WithCompleteChar(barChars[complete].char),
+		ravan.WithIncompleteChar(barChars[incomplete].char),
+	)
+	return err == nil
+}
+
+// nextChar moves a character index by step to the next valid combination.
+func (d *designer) nextChar(index *int, step int) {
+	for range barChars {
+		*index = (*index + step + len(barChars)) % len(barChars)
+		if validChars(d.complete, d.incomplete) {
+			return
+		}
+	}
+}
+
+// handle applies a key and reports whether the designer is done and
+// whether the code should be printed.
+func (d *designer) handle(key string) (done, printCode bool) {
+	switch key {
+	case "\r", "\n", "p":
+		return true, true
+	case "q", "\x1b", "\x03":
+		return true, false
+	case "+", "l", "\x1b[C":
+		d.width = min(d.width+1, 200)
+	case "-", "h", "\x1b[D":
+		d.width = max(d.width-1, 1)
+	case "c":
+		d.nextChar(&d.complete, 1)
+	case "C":
+		d.nextChar(&d.complete, -1)
+	case "i":
+		d.nextChar(&d.incomplete, 1)
+	case "I":
+		d.nextChar(&d.incomplete, -1)
+	case "t":
+		d.template = (d.template + 1) % len(templates)
+	case "T":
+		d.template = (d.template + len(templates) - 1) % len(templates)
+	case "n":
+		d.plain = !d.plain
+	case "1", "2", "3", "4":
+		n := key[0] - '1'
+		d.plain = false
+		d.colors[n] = (d.colors[n] + 1) % len(palette)
+	case "0":
+		d.plain = false
+		d.colors = [4]int{-1, -1, -1, -1}
+	}
+	return false, false
+}
+
+// theme returns the colors chosen so far, nil for the default theme.
+func (d *designer) theme() *ravan.Theme {
+	if d.plain {
+		return &ravan.Theme{}
+	}
+	if d.colors == [4]int{-1, -1, -1, -1} {
+		return nil
+	}
+
+	t := ravan.DefaultTheme
+	for i, field := range []*string{&t.Success, &t.Failed, &t.Warn, &t.Info} {
+		if d.colors[i] >= 0 {
+			*field = palette[d.colors[i]].code
+		}
+	}
+	return &t
+}
+
+// options returns the options of the design.
+func (d *designer) options() []ravan.Option {
+	opts := []ravan.Option{
+		ravan.WithWidth(d.width),
+		ravan.WithCompleteChar(barChars[d.complete].char),
+		ravan.WithIncompleteChar(barChars[d.incomplete].char),
+	}
+	if t := d.theme(); t != nil {
+		opts = append(opts, ravan.WithTheme(*t))
+	}
+	return append(opts, templates[d.template].opts...)
+}
+
+// code returns the Go code creating a bar with the design.
+func (d *designer) code() string {
+	lines := []string{
+		fmt.Sprintf("ravan.WithWidth(%d)", d.width),
+		"ravan.WithCompleteChar(ravan." + barChars[d.complete].name + ")",
+		"ravan.WithIncompleteChar(ravan." + barChars[d.incomplete].name + ")",
+	}
+	if t := d.theme(); t != nil {
+		lines = append(lines, fmt.Sprintf("ravan.WithTheme(ravan.Theme{Success: %s, Failed: %s, Warn: %s, Info: %s})",
+			quoteColor(t.Success), quoteColor(t.Failed), quoteColor(t.Warn), quoteColor(t.Info)))
+	}
+	lines = append(lines, templates[d.template].code...)
+
+	var b strings.Builder
+	b.WriteString("bar, err := ravan.New(\n")
+	for _, line := range lines {
+		b.WriteString("\t" + line + ",\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// quoteColor quotes an escape sequence the way it is written in Go code.
+func quoteColor(color string) string {
+	return strings.ReplaceAll(strconv.Quote(color), `\x1b`, `\033`)
+}
+
+// colorName returns the palette name of a theme color.
+func (d *designer) colorName(i int) string {
+	if d.plain {
+		return "none"
+	}
+	if d.colors[i] < 0 {
+		return "default"
+	}
+	return palette[d.colors[i]].name
+}
+
+// view returns the screen for a progress between 0.0 and 1.0.
+func (d *designer) view(progress float64, termWidth int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ravan interactive mode\n\n")
+	fmt.Fprintf(&b, "width %d   complete %q   incomplete %q   template %s\n",
+		d.width, barChars[d.complete].char, barChars[d.incomplete].char, templates[d.template].name)
+	fmt.Fprintf(&b, "colors: success %s, failed %s, warn %s, info %s\n\n",
+		d.colorName(0), d.colorName(1), d.colorName(2), d.colorName(3))
+
+	tpl := templates[d.template]
+	snap := tpl.snap
+	progress = min(progress, 1)
+	snap.Fraction = progress
+	snap.Current = int64(progress * float64(snap.Total))
+	if progress > 0 && progress < 1 {
+		snap.ETA = time.Duration((1 - progress) * float64(10*time.Second))
+	}
+
+	model, err := ravan.NewModel(d.options()...)
+	if err != nil {
+		fmt.Fprintf(&b, "%v\n", err)
+	} else {
+		model.Width = termWidth
+		b.WriteString(model.Update(ravan.ProgressMsg(snap)).View() + "\n\n")
+	}
+
+	// The messages are printed by a bar into a buffer to show the theme
+	var msgs bytes.Buffer
+	if bar, err := ravan.New(append(d.options(), ravan.WithWriter(&msgs))...); err == nil {
+		bar.SuccessMsg()
+		bar.WarnMsg()
+		bar.InfoMsg()
+		bar.FailMsg(errors.New("disk full"))
+	}
+	b.WriteString(strings.ReplaceAll(strings.TrimLeft(msgs.String(), "\n"), "\n\n", "\n") + "\n")
+
+	b.WriteString("←/→ width   c/C complete   i/I incomplete   t/T template\n")
+	b.WriteString("1-4 colors   0 default colors   n no colors   enter print code   q quit\n")
+	return b.String()
+}
+
+// interactive runs the designer in the terminal until the user is done.
+func interactive(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprintln(stderr, "usage: ravan interactive")
+		return 2
+	}
+
+	in, ok := stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(in.Fd())) || !isTerminal(stdout) {
+		fmt.Fprintln(stderr, "ravan: interactive mode needs a terminal")
+		return 1
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 1
+	}
+
+	keys := make(chan string)
+	go readKeys(in, keys)
+
+	d := newDesigner()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	fmt.Fprint(stdout, "\033[?25l") // hide the cursor
+	progress, printCode := 0.0, false
+	for done := false; !done; {
+		width, _, _ := term.GetSize(int(in.Fd()))
+		screen := strings.ReplaceAll(d.view(progress, width), "\n", "\r\n")
+		fmt.Fprint(stdout, "\033[H\033[2J"+screen)
+
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				done = true
+				break
+			}
+			done, printCode = d.handle(key)
+		case <-ticker.C:
+			// Rest at 100% for a moment before starting over
+			if progress += 0.01; progress > 1.2 {
+				progress = 0
+			}
+		}
+	}
+
+	fmt.Fprint(stdout, "\033[H\033[2J\033[?25h")
+	term.Restore(int(in.Fd()), state)
+	if printCode {
+		fmt.Fprint(stdout, d.code())
+	}
+	return 0
+}
+
+// escTimeout is how long an escape sequence split across reads waits for
+// its rest before the ESC counts as the escape key on its own.
+const escTimeout = 50 * time.Millisecond
+
+// readKeys sends every key read from r, keeping escape sequences of
+// arrow keys together, and closes keys at the end of the input.
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+
+	chunks := make(chan string)
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- string(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var pending string
+	var timeout <-chan time.Time
+	for {
+		flush := false
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				split, _ := splitKeys(pending, true)
+				for _, key := range split {
+					keys <- key
+				}
+				return
+			}
+			pending += chunk
+		case <-timeout:
+			flush = true
+		}
+
+		var split []string
+		split, pending = splitKeys(pending, flush)
+		for _, key := range split {
+			keys <- key
+		}
+		timeout = nil
+		if pending != "" {
+			timeout = time.After(escTimeout)
+		}
+	}
+}
+
+// splitKeys splits input into keys and returns the rest that ends inside
+// a key. With flush the rest is split too, a lone ESC being the escape key.
+func splitKeys(input string, flush bool) (keys []string, rest string) {
+	for input != "" {
+		n := keyLen(input)
+		if n == 0 {
+			if !flush {
+				return keys, input
+			}
+			n = 1
+		}
+		keys = append(keys, input[:n])
+		input = input[n:]
+	}
+	return keys, ""
+}
+
+// keyLen returns the length of the key at the start of s: a CSI escape
+// sequence such as "\x1b[C" or a single character. It is 0 if s ends
+// inside the key.
+func keyLen(s string) int {
+	if s[0] != '\x1b' {
+		if !utf8.FullRuneInString(s) {
+			return 0
+		}
+		_, n := utf8.DecodeRuneInString(s)
+		return n
+	}
+
+	if len(s) == 1 {
+		return 0
+	}
+	if s[1] != '[' {
+		return 1 // the escape key followed by another key
+	}
+	for i := 2; i < len(s); i++ {
+		switch {
+		case s[i] >= 0x40 && s[i] <= 0x7e:
+			return i + 1 // final byte
+		case s[i] < 0x20 || s[i] > 0x3f:
+			return 1 // not a CSI sequence
+		}
+	}
+	return 0
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// press sends keys to the designer and returns its last result.
+func press(d *designer, keys ...string) (done, printCode bool) {
+	for _, key := range keys {
+		done, printCode = d.handle(key)
+	}
+	return done, printCode
+}
+
+// TestDesignerCode verifies the printed code matches the chosen design.
+func TestDesignerCode(t *testing.T) {
+	d := newDesigner()
+	if done, printCode := press(d, "\x1b[D", "\x1b[D", "-", "c", "i", "i", "1", "t", "\r"); !done || !printCode {
+		t.Fatalf("enter: done %v, print %v; want both", done, printCode)
+	}
+
+	want := `bar, err := ravan.New(
+	ravan.WithWidth(47),
+	ravan.WithCompleteChar(ravan.Plus),
+	ravan.WithIncompleteChar(ravan.Asterisk),
+	ravan.WithTheme(ravan.Theme{Success: "\033[32m", Failed: "\033[31m", Warn: "\033[33m", Info: "\033[36m"}),
+	ravan.WithLabel("download"),
+)
+`
+	if got := d.code(); got != want {
+		t.Errorf("code() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestDesignerSkipsInvalidCombinations verifies every combination offered is
+// accepted by ravan.New.
+func TestDesignerSkipsInvalidCombinations(t *testing.T) {
+	d := newDesigner()
+	seen := map[string]bool{}
+	for i := 0; i < len(barChars); i++ {
+		press(d, "c")
+		if !validChars(d.complete, d.incomplete) {
+			t.Fatalf("invalid combination %q %q", barChars[d.complete].char, barChars[d.incomplete].char)
+		}
+		seen[string(barChars[d.complete].char)] = true
+	}
+	if seen[" "] || !seen["="] || len(seen) != len(barChars)-1 {
+		t.Errorf("expected every complete character but the empty one, got %v", seen)
+	}
+
+	press(d, "i", "i")
+	if d.complete == d.incomplete {
+		t.Error("incomplete character must skip the complete one")
+	}
+}
+
+// TestDesignerView verifies the preview uses the design, including colors.
+func TestDesignerView(t *testing.T) {
+	d := newDesigner()
+	press(d, "n", "t", "t")
+
+	screen := ravantest.NewTerminal(100)
+	screen.Write([]byte(d.view(0.5, 100)))
+	got := screen.String()
+	for _, want := range []string{
+		"template stats",
+		"colors: success none, failed none, warn none, info none",
+		"[=========================                         ] 50% 2.0 GiB/4.0 GiB 35.0 MiB/s ETA 5s",
+		"Success: Operation successful",
+		"Error: disk full. Operation failed",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("view is missing %q:\n%s", want, got)
+		}
+	}
+	if styled := screen.StyledString(); strings.Contains(styled, "<") {
+		t.Errorf("expected no colors, got\n%s", styled)
+	}
+}
+
+func TestDesignerQuit(t *testing.T) {
+	for _, key := range []string{"q", "\x1b", "\x03"} {
+		if done, printCode := press(newDesigner(), key); !done || printCode {
+			t.Errorf("key %q: done %v, print %v; want quit without code", key, done, printCode)
+		}
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		flush bool
+		keys  []string
+		rest  string
+	}{
+		{"\x1b[C\x1b[C", false, []string{"\x1b[C", "\x1b[C"}, ""},
+		{"\x1b[Dc\x1b[1;5C", false, []string{"\x1b[D", "c", "\x1b[1;5C"}, ""},
+		{"a\x1b", false, []string{"a"}, "\x1b"},
+		{"\x1b[", false, nil, "\x1b["},
+		{"\x1b", true, []string{"\x1b"}, ""},
+		{"\x1bq", false, []string{"\x1b", "q"}, ""},
+		{"ش\xd8", false, []string{"ش"}, "\xd8"},
+	}
+	for _, tt := range tests {
+		keys, rest := splitKeys(tt.input, tt.flush)
+		if strings.Join(keys, "|") != strings.Join(tt.keys, "|") || rest != tt.rest {
+			t.Errorf("splitKeys(%q, %v) = %q, %q; want %q, %q", tt.input, tt.flush, keys, rest, tt.keys, tt.rest)
+		}
+	}
+}
+
+// TestReadKeys verifies keys are put together across reads and a lone
+// ESC is sent once no more input follows.
+func TestReadKeys(t *testing.T) {
+	pr, pw := io.Pipe()
+	keys := make(chan string)
+	go readKeys(pr, keys)
+
+	pw.Write([]byte("\x1b"))
+	pw.Write([]byte("[C\x1b[Cq"))
+	for _, want := range []string{"\x1b[C", "\x1b[C", "q"} {
+		if got := <-keys; got != want {
+			t.Fatalf("key %q; want %q", got, want)
+		}
+	}
+
+	pw.Write([]byte("\x1b"))
+	select {
+	case got := <-keys:
+		if got != "\x1b" {
+			t.Errorf("key %q; want a lone ESC", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a lone ESC was not sent")
+	}
+
+	pw.Close()
+	if _, ok := <-keys; ok {
+		t.Error("keys must be closed at the end of the input")
+	}
+}
+
+func TestInteractiveNeedsTerminal(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"interactive"}, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("exit code %d; want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "needs a terminal") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
@@ -0,0 +1,54 @@
+// Command ravan shows the progress of data flowing through a shell pipeline:
+//
+//	cat big.tar | ravan -s 4G | tar x
+//
+// It copies stdin to stdout and draws the bar with rate and ETA on stderr.
+//
+// ravan exec runs a command and turns the numbers in its output into progress:
+//
+//	ravan exec --pattern '(\d+)/(\d+)' -- ./migrate.sh
+//
+// ravan interactive previews characters, colors and templates live and
+// prints the Go options of the chosen design.
+//
+// ravan serve draws bars that shell scripts update with ravan send:
+//
+//	ravan serve --socket /tmp/p.sock &
+//	ravan send --socket /tmp/p.sock set 30
+//	ravan send --socket /tmp/p.sock msg "copying"
+//	ravan send --socket /tmp/p.sock done
+package main
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "exec":
+			return execCmd(args[1:], stdin, stdout, stderr)
+		case "interactive":
+			return interactive(args[1:], stdin, stdout, stderr)
+		case "serve":
+			return serveCmd(args[1:], stdin, stdout, stderr)
+		case "send":
+			return sendCmd(args[1:], stdin, stdout, stderr)
+		}
+	}
+	return pipe(args, stdin, stdout, stderr)
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
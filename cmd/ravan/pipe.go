@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pooulad/ravan"
+)
+
+// pipe copies stdin to stdout and shows the progress on stderr.
+func pipe(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ravan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	size := fs.String("s", "", "size of the input, e.g. 4G (default: size of a file on stdin)")
+	lines := fs.Bool("l", false, "count lines instead of bytes")
+	quiet := fs.Bool("q", false, "copy without showing the bar")
+	force := fs.Bool("f", false, "show the bar even if stderr is not a terminal")
+	name := fs.String("N", "", "label shown in front of the bar")
+	width := fs.Int("w", 0, "bar width (default 50)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ravan [flags] < input > output")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	total, err := parseSize(*size)
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+	if total == 0 && !*lines {
+		total = fileSize(stdin)
+	}
+
+	// Like pv, stay silent when stderr was redirected away from a terminal
+	if _, ok := stderr.(*os.File); ok && !*force && !isTerminal(stderr) {
+		*quiet = true
+	}
+	if *quiet {
+		if _, err := io.Copy(stdout, stdin); err != nil {
+			fmt.Fprintln(stderr, "ravan:", err)
+			return 1
+		}
+		return 0
+	}
+
+	units := ravan.Bytes
+	if *lines {
+		units = ravan.Items
+	}
+	opts, err := ravan.LoadConfig("")
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+	opts = append(opts,
+		ravan.WithWriter(stderr),
+		ravan.WithTotal(total),
+		ravan.WithStats(units),
+		ravan.WithLabel(*name),
+		ravan.WithMaxFPS(10),
+	)
+	if *width > 0 {
+		opts = append(opts, ravan.WithWidth(*width))
+	}
+	bar, err := ravan.New(opts...)
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+
+	bar.Start()
+	_, err = io.Copy(&countingWriter{w: stdout, c: bar.Counter(), lines: *lines}, stdin)
+	bar.Finish(err)
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// countingWriter counts the bytes or lines written through it.
+type countingWriter struct {
+	w     io.Writer
+	c     *ravan.Counter
+	lines bool
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	if cw.lines {
+		cw.c.Add(int64(bytes.Count(p[:n], []byte{'\n'})))
+	} else {
+		cw.c.Add(int64(n))
+	}
+	return n, err
+}
+
+// fileSize returns the size of a regular file on stdin, or 0.
+func fileSize(r io.Reader) int64 {
+	f, ok := r.(*os.File)
+	if !ok {
+		return 0
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
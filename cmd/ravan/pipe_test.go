@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// TestMain clears the environment that changes the output of the bar.
+func TestMain(m *testing.M) {
+	for _, key := range []string{"RAVAN_CONFIG", "RAVAN_OUTPUT", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		os.Unsetenv(key)
+	}
+	os.Exit(m.Run())
+}
+
+// TestPipe verifies the input is copied unchanged while the bar goes to stderr.
+func TestPipe(t *testing.T) {
+	input := strings.Repeat("x", 2048)
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"-s", "2K", "-N", "copy"}, strings.NewReader(input), &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr.String())
+	}
+	if stdout.String() != input {
+		t.Errorf("stdout has %d bytes; want the %d input bytes", stdout.Len(), len(input))
+	}
+	if got := stderr.String(); !strings.Contains(got, "] 100% 2.0 KiB/2.0 KiB") || !strings.Contains(got, "copy [") {
+		t.Errorf("expected a labeled complete bar with sizes, got %q", got)
+	}
+}
+
+// TestPipeLines verifies line mode counts lines and shows no bar without a size.
+func TestPipeLines(t *testing.T) {
+	var stdout bytes.Buffer
+	stderr := ravantest.NewTerminal(80)
+
+	if code := run([]string{"-l"}, strings.NewReader("a\nb\nc\n"), &stdout, stderr); code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr.String())
+	}
+	if got := stderr.Lines()[0]; !strings.HasPrefix(got, "3 ") || !strings.HasSuffix(got, "/s") {
+		t.Errorf("expected a line count and rate without bar, got %q", got)
+	}
+}
+
+// TestPipeFileSize verifies the size of a file on stdin is used as total.
+func TestPipeFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, make([]byte, 3000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var stdout bytes.Buffer
+	stderr := ravantest.NewTerminal(80)
+	run([]string{"-w", "10"}, f, &stdout, stderr)
+	if got := stderr.Lines()[0]; !strings.HasPrefix(got, "[==========] 100% 2.9 KiB/2.9 KiB ") {
+		t.Errorf("expected the file size as total, got %q", got)
+	}
+}
+
+func TestPipeQuiet(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"-q"}, strings.NewReader("data"), &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if stdout.String() != "data" || stderr.Len() != 0 {
+		t.Errorf("stdout %q, stderr %q; want the data and no bar", stdout.String(), stderr.String())
+	}
+}
+
+func TestPipeUsage(t *testing.T) {
+	for _, args := range [][]string{{"-s", "big"}, {"-x"}, {"file"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, strings.NewReader(""), &stdout, &stderr); code != 2 {
+			t.Errorf("run(%q) = %d; want 2", args, code)
+		}
+	}
+}
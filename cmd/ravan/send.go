@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// dialTimeout is how long send waits for a server that is just starting,
+// so scripts can run ravan serve in the background and send right away.
+const dialTimeout = 2 * time.Second
+
+// sendCmd sends one command to ravan serve.
+func sendCmd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ravan send", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	socket := fs.String("socket", os.Getenv(SocketEnv), "path of the Unix socket (default $"+SocketEnv+")")
+	name := fs.String("N", "", "name of the bar, shown as its label")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ravan send [flags] command [args...]")
+		fmt.Fprintln(stderr, "commands: set PERCENT|N/M, label TEXT, msg [TEXT], warn [TEXT], done, fail [TEXT], quit")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || *socket == "" {
+		fs.Usage()
+		return 2
+	}
+
+	conn, err := dialUnix(*socket, dialTimeout)
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 1
+	}
+	defer conn.Close()
+
+	req := request{Bar: *name, Cmd: fs.Arg(0), Args: fs.Args()[1:]}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 1
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		fmt.Fprintln(stderr, "ravan: no response from server:", err)
+		return 1
+	}
+	if resp.Error != "" {
+		fmt.Fprintln(stderr, "ravan:", resp.Error)
+		return 1
+	}
+	return 0
+}
+
+// dialUnix connects to a Unix socket, retrying until timeout while the
+// socket doesn't exist yet or refuses connections.
+func dialUnix(path string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.Dial("unix", path)
+		if err == nil || time.Now().After(deadline) {
+			return conn, err
+		}
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
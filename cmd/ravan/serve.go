@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pooulad/ravan"
+	"golang.org/x/term"
+)
+
+// SocketEnv is the environment variable with the default socket of serve and send.
+const SocketEnv = "RAVAN_SOCKET"
+
+// request is one command sent to ravan serve, written as a JSON line.
+type request struct {
+	Bar  string   `json:"bar"`
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args,omitempty"`
+}
+
+// response answers a request, Error is empty on success.
+type response struct {
+	Error string `json:"error,omitempty"`
+}
+
+// serveCmd owns the bars and draws them while scripts update them with ravan send.
+func serveCmd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ravan serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	socket := fs.String("socket", os.Getenv(SocketEnv), "path of the Unix socket (default $"+SocketEnv+")")
+	keep := fs.Bool("keep", false, "keep running when all bars are finished, until ravan send quit")
+	width := fs.Int("w", 0, "bar width (default 50)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ravan serve [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 || *socket == "" {
+		fs.Usage()
+		return 2
+	}
+
+	opts, err := ravan.LoadConfig("")
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+	if *width > 0 {
+		opts = append(opts, ravan.WithWidth(*width))
+	}
+	if _, err := ravan.New(opts...); err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 2
+	}
+
+	ln, err := listenUnix(*socket)
+	if err != nil {
+		fmt.Fprintln(stderr, "ravan:", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := &server{board: newBoard(stderr, opts), keep: *keep, ln: ln, conns: map[net.Conn]struct{}{}}
+	s.serve(ctx)
+
+	if s.board.failed() {
+		return 1
+	}
+	return 0
+}
+
+// listenUnix listens on a Unix socket and replaces the socket file of a
+// server that is no longer running. The file is removed when the
+// listener is closed.
+func listenUnix(path string) (net.Listener, error) {
+	ln, err := net.Listen("unix", path)
+	if err == nil {
+		return ln, nil
+	}
+	if _, statErr := os.Stat(path); statErr != nil {
+		return nil, err
+	}
+	if conn, dialErr := net.Dial("unix", path); dialErr == nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s is used by another server", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// server accepts the connections of ravan send.
+type server struct {
+	board *board
+	keep  bool
+	ln    net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// serve handles connections until quit is sent, all bars are finished
+// (unless keep is set) or ctx is done.
+func (s *server) serve(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		s.ln.Close()
+	}()
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			break
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+
+	// Clients that are still connected are not waited for
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// handle answers the requests of one connection.
+func (s *server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				enc.Encode(response{Error: "invalid request: " + err.Error()})
+			}
+			return
+		}
+
+		var resp response
+		if req.Cmd == "quit" {
+			enc.Encode(resp)
+			s.ln.Close()
+			return
+		}
+		if err := s.board.do(req); err != nil {
+			resp.Error = err.Error()
+		}
+		enc.Encode(resp)
+
+		if !s.keep && s.board.finished() {
+			s.ln.Close()
+			return
+		}
+	}
+}
+
+// board draws named bars below each other and prints the messages of
+// all bars above them.
+type board struct {
+	w    io.Writer
+	opts []ravan.Option
+
+	mu    sync.Mutex
+	bars  []*boardBar
+	lines int // bar lines drawn above the cursor
+}
+
+// boardBar is a named bar and the view of its last update.
+type boardBar struct {
+	name  string
+	bar   *ravan.Ravan
+	model ravan.Model
+}
+
+func newBoard(w io.Writer, opts []ravan.Option) *board {
+	return &board{w: w, opts: opts}
+}
+
+// do applies a request to its bar.
+func (b *board) do(req request) error {
+	text := strings.Join(req.Args, " ")
+
+	// Bars are created on first use, but not by a mistyped command
+	switch req.Cmd {
+	case "set", "label", "msg", "warn", "done", "fail":
+	case "":
+		return errors.New("missing command")
+	default:
+		return fmt.Errorf("unknown command %q", req.Cmd)
+	}
+	bar, err := b.bar(req.Bar)
+	if err != nil {
+		return err
+	}
+	if bar.Status() != ravan.Running {
+		return fmt.Errorf("bar %q is finished", req.Bar)
+	}
+
+	switch req.Cmd {
+	case "set":
+		progress, err := parseProgress(text)
+		if err != nil {
+			return err
+		}
+		bar.Draw(progress)
+	case "label":
+		bar.SetLabel(text)
+	case "msg":
+		bar.InfoMsg(msgArgs(text)...)
+	case "warn":
+		bar.WarnMsg(msgArgs(text)...)
+	case "done":
+		bar.Draw(1)
+		bar.Finish(nil)
+	case "fail":
+		if text == "" {
+			text = "failed"
+		}
+		bar.Finish(errors.New(text))
+	}
+	return nil
+}
+
+// msgArgs returns the arguments of InfoMsg and WarnMsg for text,
+// none for the default message.
+func msgArgs(text string) []interface{} {
+	if text == "" {
+		return nil
+	}
+	return []interface{}{text}
+}
+
+// bar returns the bar with the name, creating it if needed.
+// The bar of the empty name has no label.
+func (b *board) bar(name string) (*ravan.Ravan, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range b.bars {
+		if e.name == name {
+			return e.bar, nil
+		}
+	}
+
+	opts := append(append([]ravan.Option{}, b.opts...), ravan.WithLabel(name))
+	model, err := ravan.NewModel(opts...)
+	if err != nil {
+		return nil, err
+	}
+	e := &boardBar{name: name, model: model}
+	// The renderer runs with the lock of the bar held, so the board
+	// never calls a bar while holding its own lock
+	render := ravan.MsgRenderer(func(msg interface{}) { b.update(e, msg) })
+	e.bar, err = ravan.New(append(opts, ravan.WithRenderer(render))...)
+	if err != nil {
+		return nil, err
+	}
+
+	b.bars = append(b.bars, e)
+	b.redraw("")
+	return e.bar, nil
+}
+
+// update applies a message of a bar and draws the board again.
+func (b *board) update(e *boardBar, msg interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.model = e.model.Update(msg)
+	above := ""
+	if n, ok := msg.(ravan.NoticeMsg); ok {
+		above = e.model.Message(n)
+		if e.name != "" {
+			above = e.name + ": " + above
+		}
+	}
+	b.redraw(above)
+}
+
+// redraw prints the line above, if any, and the bars below it.
+// b.mu must be held.
+func (b *board) redraw(above string) {
+	var out strings.Builder
+	if b.lines > 0 {
+		fmt.Fprintf(&out, "\033[%dA", b.lines)
+	}
+	if above != "" {
+		out.WriteString("\r" + above + "\033[K\n")
+	}
+
+	width := writerWidth(b.w)
+	for _, e := range b.bars {
+		e.model.Width = width
+		out.WriteString("\r" + e.model.View() + "\033[K\n")
+	}
+	b.lines = len(b.bars)
+	io.WriteString(b.w, out.String())
+}
+
+// finished reports whether there are bars and all of them are finished.
+func (b *board) finished() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range b.bars {
+		if e.model.Snapshot().Status == ravan.Running {
+			return false
+		}
+	}
+	return len(b.bars) > 0
+}
+
+// failed reports whether a bar was finished with fail.
+func (b *board) failed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range b.bars {
+		if e.model.Snapshot().Status == ravan.Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProgress reads "30", "30%" or "3/10" as a progress between 0.0 and 1.0.
+func parseProgress(s string) (float64, error) {
+	if current, total, ok := strings.Cut(s, "/"); ok {
+		c, err1 := strconv.ParseFloat(strings.TrimSpace(current), 64)
+		t, err2 := strconv.ParseFloat(strings.TrimSpace(total), 64)
+		if err1 != nil || err2 != nil || c < 0 || t <= 0 {
+			return 0, fmt.Errorf("invalid progress %q", s)
+		}
+		return min(c/t, 1), nil
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("invalid progress %q, want a percent or current/total", s)
+	}
+	return percent / 100, nil
+}
+
+// writerWidth returns the width of a terminal or of a writer with a
+// Width method such as ravantest.Terminal, 0 otherwise.
+func writerWidth(w io.Writer) int {
+	if s, ok := w.(interface{ Width() int }); ok {
+		return s.Width()
+	}
+	if f, ok := w.(*os.File); ok && isTerminal(f) {
+		width, _, _ := term.GetSize(int(f.Fd()))
+		return width
+	}
+	return 0
+}
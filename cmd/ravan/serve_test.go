@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// startServe runs ravan serve in the background and returns the socket,
+// the screen and a channel with the exit code.
+func startServe(t *testing.T, flags ...string) (string, *ravantest.Terminal, <-chan int) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "p.sock")
+	screen := ravantest.NewTerminal(60)
+	exit := make(chan int, 1)
+	go func() {
+		args := append([]string{"serve", "-socket", socket, "-w", "10"}, flags...)
+		exit <- run(args, strings.NewReader(""), &bytes.Buffer{}, screen)
+	}()
+	return socket, screen, exit
+}
+
+// send runs ravan send and returns the exit code and stderr.
+func send(t *testing.T, socket string, args ...string) (int, string) {
+	t.Helper()
+	var stderr bytes.Buffer
+	code := run(append([]string{"send", "-socket", socket}, args...), strings.NewReader(""), &bytes.Buffer{}, &stderr)
+	return code, stderr.String()
+}
+
+// waitExit returns the exit code of ravan serve.
+func waitExit(t *testing.T, exit <-chan int) int {
+	t.Helper()
+	select {
+	case code := <-exit:
+		return code
+	case <-time.After(5 * time.Second):
+		t.Fatal("ravan serve did not exit")
+		return 0
+	}
+}
+
+// TestServe verifies named bars are drawn below each other with their
+// messages above, and the server exits once every bar is done.
+func TestServe(t *testing.T) {
+	socket, screen, exit := startServe(t)
+
+	for _, args := range [][]string{
+		{"-N", "db", "set", "30"},
+		{"-N", "web", "set", "1/4"},
+		{"-N", "db", "msg", "copying"},
+		{"-N", "web", "warn"},
+		{"-N", "db", "set", "50%"},
+		{"-N", "db", "done"},
+		{"-N", "web", "done"},
+	} {
+		if code, stderr := send(t, socket, args...); code != 0 {
+			t.Fatalf("send %v: exit code %d, %s", args, code, stderr)
+		}
+	}
+
+	if code := waitExit(t, exit); code != 0 {
+		t.Errorf("exit code %d; want 0", code)
+	}
+	want := []string{
+		"db: Info: copying",
+		"web: Warning: Operation completed with warnings",
+		"db: Success: Operation successful",
+		"web: Success: Operation successful (1 warning)",
+		"db [==========] 100%",
+		"web [==========] 100%",
+	}
+	if got := strings.TrimSpace(screen.String()); got != strings.Join(want, "\n") {
+		t.Errorf("screen:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+// TestServeFail verifies a failed bar is shown as a failure and sets the exit code.
+func TestServeFail(t *testing.T) {
+	socket, screen, exit := startServe(t)
+
+	send(t, socket, "set", "40")
+	if code, _ := send(t, socket, "fail", "deploy", "broke"); code != 0 {
+		t.Fatalf("send fail: exit code %d", code)
+	}
+
+	if code := waitExit(t, exit); code != 1 {
+		t.Errorf("exit code %d; want 1", code)
+	}
+	got := screen.String()
+	if !strings.Contains(got, "Error: deploy broke. Operation failed") || !strings.Contains(got, "[====      ] 40%") {
+		t.Errorf("expected the bar at 40%% and a failure, got\n%s", got)
+	}
+}
+
+// TestServeKeep verifies -keep waits for quit and invalid commands are reported.
+func TestServeKeep(t *testing.T) {
+	socket, _, exit := startServe(t, "-keep")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"set", "120"}, `invalid progress "120"`},
+		{[]string{"jump"}, `unknown command "jump"`},
+		{[]string{"done"}, ""},
+		{[]string{"msg", "late"}, `bar "" is finished`},
+	}
+	for _, tt := range tests {
+		code, stderr := send(t, socket, tt.args...)
+		if tt.want == "" && code != 0 {
+			t.Errorf("send %v: exit code %d, %s", tt.args, code, stderr)
+		}
+		if tt.want != "" && (code != 1 || !strings.Contains(stderr, tt.want)) {
+			t.Errorf("send %v = %d, %q; want an error containing %q", tt.args, code, stderr, tt.want)
+		}
+	}
+
+	if code, _ := send(t, socket, "quit"); code != 0 {
+		t.Errorf("send quit: exit code %d", code)
+	}
+	if code := waitExit(t, exit); code != 0 {
+		t.Errorf("exit code %d; want 0", code)
+	}
+	if code, _ := send(t, socket, "set", "10"); code == 0 {
+		t.Error("expected send to fail after the server quit")
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	tests := map[string]float64{"30": 0.3, "45.5%": 0.455, "3/4": 0.75, "5/4": 1, "100": 1}
+	for input, want := range tests {
+		if got, err := parseProgress(input); err != nil || got != want {
+			t.Errorf("parseProgress(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "abc", "-1", "101%", "1/0"} {
+		if _, err := parseProgress(input); err == nil {
+			t.Errorf("parseProgress(%q) expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"100", 100},
+		{"4k", 4096},
+		{"1.5M", 1536 * 1024},
+		{"4G", 4 << 30},
+		{"2GiB", 2 << 30},
+		{"1TB", 1 << 40},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"G", "-1K", "4X", "lots"} {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%q): expected error", in)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package ravan
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConfigEnv names the environment variable with the path of the config file
+// read by LoadConfig when it is called with an empty path.
+const ConfigEnv = "RAVAN_CONFIG"
+
+// configKeys lists the settings of a config file. Each key is also read
+// from the environment in upper case with a RAVAN_ prefix, e.g. RAVAN_WIDTH.
+var configKeys = []string{
+	"width",
+	"complete_char",
+	"incomplete_char",
+	"theme",
+	"color_success",
+	"color_failed",
+	"color_warn",
+	"color_info",
+	"output",
+	"language",
+	"max_fps",
+}
+
+// LoadConfig reads the bar settings chosen by the end user of a program:
+// an optional config file and RAVAN_* environment variables, which win over
+// the file. The file is a flat JSON object (.json), TOML (.toml) or YAML
+// (.yaml, .yml) file with the keys
+//
+//	width            bar width, e.g. 40
+//	complete_char    e.g. "#"
+//	incomplete_char  e.g. "-"
+//	theme            "default" or "plain" for no colors
+//	color_success    SGR color of success messages, e.g. "32"
+//	color_failed     same for failures, warnings and info messages
+//	color_warn
+//	color_info
+//	output           "terminal", "json" or "accessible"
+//	language         language tag, e.g. "fa"
+//	max_fps          redraws per second, 0 for unlimited
+//
+// An empty path reads the file named by RAVAN_CONFIG, if set. A file that
+// doesn't exist is skipped. Values are checked like the matching options,
+// e.g. complete_char by WithCompleteChar.
+//
+// Pass the options before your own so explicit options take precedence:
+//
+//	opts, err := ravan.LoadConfig("")
+//	bar, err := ravan.New(append(opts, ravan.WithTotal(n))...)
+func LoadConfig(path string) ([]Option, error) {
+	if path == "" {
+		path = os.Getenv(ConfigEnv)
+	}
+
+	values := map[string]string{}
+	if path != "" {
+		file, err := readConfigFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for key, value := range file {
+			values[key] = value
+		}
+	}
+	for _, key := range configKeys {
+		if value, ok := os.LookupEnv(configEnvName(key)); ok {
+			values[key] = value
+		}
+	}
+
+	// Apply in the order of configKeys so colors override the theme
+	var opts []Option
+	for _, key := range configKeys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		opt, err := configOption(key, value)
+		if err == nil {
+			err = opt(&Ravan{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", configSource(key, path), err)
+		}
+		opts = append(opts, opt)
+	}
+
+	// Settings that are only invalid together, like equal characters
+	if _, err := New(opts...); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return opts, nil
+}
+
+// configEnvName returns the environment variable of a config key.
+func configEnvName(key string) string {
+	return "RAVAN_" + strings.ToUpper(key)
+}
+
+// configSource names where a setting came from for error messages.
+func configSource(key, path string) string {
+	name := configEnvName(key)
+	if _, ok := os.LookupEnv(name); ok {
+		return name
+	}
+	return path + ": " + key
+}
+
+// configOption turns a setting into the Option that validates and applies it.
+func configOption(key, value string) (Option, error) {
+	switch key {
+	case "width":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid width: %s", value)
+		}
+		return WithWidth(n), nil
+	case "complete_char":
+		return WithCompleteChar(BarCharacter(value)), nil
+	case "incomplete_char":
+		return WithIncompleteChar(BarCharacter(value)), nil
+	case "theme":
+		switch value {
+		case "default":
+			return WithTheme(DefaultTheme), nil
+		case "plain":
+			return WithTheme(Theme{}), nil
+		}
+		return nil, fmt.Errorf("invalid theme: %s", value)
+	case "color_success", "color_failed", "color_warn", "color_info":
+		if !isValidColor(value) {
+			return nil, fmt.Errorf("invalid color: %s", value)
+		}
+		return withColor(strings.TrimPrefix(key, "color_"), value), nil
+	case "output":
+		return WithOutput(OutputMode(value)), nil
+	case "language":
+		return WithLanguage(value), nil
+	case "max_fps":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max FPS: %s", value)
+		}
+		return WithMaxFPS(n), nil
+	}
+	return nil, fmt.Errorf("unknown setting")
+}
+
+// withColor changes a single color of the theme to the SGR parameters color.
+func withColor(name, color string) Option {
+	if color != "" {
+		color = "\033[" + color + "m"
+	}
+	return func(r *Ravan) error {
+		t := r.colors()
+		switch name {
+		case "success":
+			t.Success = color
+		case "failed":
+			t.Failed = color
+		case "warn":
+			t.Warn = color
+		case "info":
+			t.Info = color
+		}
+		r.theme = &t
+		return nil
+	}
+}
+
+// isValidColor reports whether color is a list of SGR parameters like "1;32".
+// An empty color disables colors.
+func isValidColor(color string) bool {
+	for _, c := range color {
+		if (c < '0' || c > '9') && c != ';' {
+			return false
+		}
+	}
+	return true
+}
+
+// readConfigFile reads the flat key/value settings of a config file.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		values, err = parseJSONConfig(data)
+	case ".toml":
+		values, err = parseFlatConfig(data, "=")
+	case ".yaml", ".yml":
+		values, err = parseFlatConfig(data, ":")
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format %q", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for key := range values {
+		if !isConfigKey(key) {
+			return nil, fmt.Errorf("%s: unknown setting %q", path, key)
+		}
+	}
+	return values, nil
+}
+
+// parseJSONConfig reads a flat JSON object. Numbers are kept as written.
+func parseJSONConfig(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, msg := range raw {
+		var s string
+		if err := json.Unmarshal(msg, &s); err == nil {
+			values[key] = s
+			continue
+		}
+		var n json.Number
+		if err := json.Unmarshal(msg, &n); err != nil {
+			return nil, fmt.Errorf("%s must be a string or a number", key)
+		}
+		values[key] = n.String()
+	}
+	return values, nil
+}
+
+// parseFlatConfig reads "key = value" (TOML) or "key: value" (YAML) lines.
+// Values may be quoted; blank lines and # comments are skipped.
+// Tables, lists and nested mappings are not supported.
+func parseFlatConfig(data []byte, sep string) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, sep)
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key%svalue", n, sep)
+		}
+		value, err := unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, scanner.Err()
+}
+
+// unquote removes the quotes of a "double" or 'single' quoted value,
+// or a trailing # comment of an unquoted one.
+func unquote(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '"', '\'':
+		end := strings.IndexByte(value[1:], quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		rest := strings.TrimSpace(value[end+2:])
+		if rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		return value[1 : end+1], nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
+
+// isConfigKey reports whether key is a known setting.
+func isConfigKey(key string) bool {
+	for _, k := range configKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package ravan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file into a temporary directory.
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadConfigFormats verifies the same settings are read from every format.
+func TestLoadConfigFormats(t *testing.T) {
+	files := map[string]string{
+		"ravan.toml": `# bar style
+width = 40
+complete_char = "#"
+incomplete_char = '-'  # dashes
+theme = "plain"
+color_success = "1;32"
+max_fps = 10
+`,
+		"ravan.yaml": `---
+width: 40
+complete_char: "#"
+incomplete_char: -
+theme: plain
+color_success: '1;32'
+max_fps: 10
+`,
+		"ravan.json": `{"width": 40, "complete_char": "#", "incomplete_char": "-", "theme": "plain", "color_success": "1;32", "max_fps": 10}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			opts, err := LoadConfig(writeConfig(t, name, content))
+			if err != nil {
+				t.Fatalf("LoadConfig() error: %v", err)
+			}
+
+			r, err := New(opts...)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			if r.width != 40 || r.completeChar != Hash || r.incompleteChar != Dash || r.maxFPS != 10 {
+				t.Errorf("unexpected settings: width %d, chars %q %q, max FPS %d",
+					r.width, r.completeChar, r.incompleteChar, r.maxFPS)
+			}
+			if got := r.colors(); got != (Theme{Success: "\033[1;32m"}) {
+				t.Errorf("theme = %+v; want only the success color", got)
+			}
+		})
+	}
+}
+
+// TestLoadConfigPrecedence verifies the environment wins over the file
+// and explicit options win over both.
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfig(t, "ravan.toml", "width = 40\ncomplete_char = \"#\"\n")
+	t.Setenv("RAVAN_WIDTH", "30")
+	t.Setenv("RAVAN_OUTPUT", "json")
+
+	opts, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	r, _ := New(append(opts, WithCompleteChar(Plus))...)
+	if r.width != 30 || r.completeChar != Plus || r.mode != JSONOutput {
+		t.Errorf("unexpected settings: width %d, complete %q, output %s", r.width, r.completeChar, r.mode)
+	}
+}
+
+func TestLoadConfigFromEnvPath(t *testing.T) {
+	t.Setenv(ConfigEnv, writeConfig(t, "ravan.yml", "language: de\n"))
+
+	opts, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if r, _ := New(opts...); r.locale != GermanLocale {
+		t.Errorf("expected the German locale, got %+v", r.locale)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	opts, err := LoadConfig(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil || len(opts) != 0 {
+		t.Errorf("LoadConfig() = %d options, %v; want none and no error", len(opts), err)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{"invalid char", "ravan.toml", `complete_char = "x"`, nil, "complete_char: invalid complete character: x"},
+		{"same chars", "ravan.toml", "complete_char = \"-\"\nincomplete_char = \"-\"", nil, "characters must differ"},
+		{"env char", "ravan.toml", "", map[string]string{"RAVAN_INCOMPLETE_CHAR": "x"}, "RAVAN_INCOMPLETE_CHAR: invalid incomplete character"},
+		{"width", "ravan.yaml", "width: wide", nil, "invalid width: wide"},
+		{"theme", "ravan.yaml", "theme: neon", nil, "invalid theme: neon"},
+		{"color", "ravan.json", `{"color_info": "cyan"}`, nil, "invalid color: cyan"},
+		{"output", "ravan.json", `{"output": "html"}`, nil, "invalid output mode: html"},
+		{"language", "ravan.json", `{"language": "xx"}`, nil, `unknown language "xx"`},
+		{"unknown key", "ravan.toml", "colour = 32", nil, `unknown setting "colour"`},
+		{"syntax", "ravan.toml", "width 40", nil, "line 1: expected key=value"},
+		{"unterminated", "ravan.yaml", `complete_char: "#`, nil, "unterminated string"},
+		{"json type", "ravan.json", `{"width": [40]}`, nil, "width must be a string or a number"},
+		{"format", "ravan.ini", "width=40", nil, `unsupported config format ".ini"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := LoadConfig(writeConfig(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig() error = %v; want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package ravan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// TreeEntry is a directory, regular file or symlink found by ScanTree.
+type TreeEntry struct {
+	Path string      // slash-separated path relative to the root, "." for the root
+	Mode fs.FileMode // type and permission bits
+	Size int64       // size of a regular file
+}
+
+// Tree is a directory tree scanned before copying, so the bar knows
+// the total bytes and files up front.
+type Tree struct {
+	Root    string
+	Entries []TreeEntry // in lexical order, directories before their contents
+	Files   int         // regular files
+	Bytes   int64       // bytes of all regular files
+	Errors  []FileError // entries that could not be read
+}
+
+// FileError is the failure of a single file while scanning or copying a tree.
+type FileError struct {
+	Path string // path relative to the root of the tree
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// fileError returns a FileError for path without the path repeated by
+// errors of the os package.
+func fileError(path string, err error) FileError {
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.As(err, &pathErr):
+		err = pathErr.Err
+	case errors.As(err, &linkErr):
+		err = linkErr.Err
+	}
+	return FileError{Path: path, Err: err}
+}
+
+// CopyReport summarizes a copy of a tree.
+type CopyReport struct {
+	Files  int   // regular files copied
+	Bytes  int64 // bytes copied
+	Dirs   int   // directories created
+	Links  int   // symlinks created
+	Errors []FileError
+}
+
+// ScanTree walks root with filepath.WalkDir and counts the files and bytes
+// to copy. Symlinks are listed as links and not followed. Entries that
+// can't be read are collected in Errors; only an unreadable root is an error.
+func ScanTree(root string) (*Tree, error) {
+	t := &Tree{Root: root}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			t.Errors = append(t.Errors, fileError(t.rel(path), err))
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			t.Errors = append(t.Errors, fileError(t.rel(path), err))
+			return nil
+		}
+		entry := TreeEntry{Path: t.rel(path), Mode: info.Mode()}
+		if info.Mode().IsRegular() {
+			entry.Size = info.Size()
+			t.Files++
+			t.Bytes += info.Size()
+		}
+		t.Entries = append(t.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// rel returns path relative to the root of the tree.
+func (t *Tree) rel(path string) string {
+	rel, err := filepath.Rel(t.Root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// CopyTree scans src and copies it to dst, see Tree.Copy.
+func CopyTree(ctx context.Context, src, dst string, opts ...Option) (CopyReport, error) {
+	t, err := ScanTree(src)
+	if err != nil {
+		return CopyReport{}, err
+	}
+	return t.Copy(ctx, dst, opts...)
+}
+
+// Copy copies the tree to dst with a bar of the bytes copied, showing
+// the files done and the current file as label, e.g.
+// "3/120 docs/a.txt [====      ] 40% 1.2 GiB/3.0 GiB 85.0 MiB/s ETA 21s".
+// The bar is created with WithTotal and WithStats(Bytes) followed by opts.
+//
+// Symlinks are copied as links, permissions and modification times are
+// kept. A file that fails is reported with WarnMsg and copying goes on;
+// the bar then finishes with the number of failed files and the error
+// returned lists them in the report. Canceling ctx stops the copy and
+// finishes the bar as aborted.
+func (t *Tree) Copy(ctx context.Context, dst string, opts ...Option) (CopyReport, error) {
+	r, err := New(append([]Option{WithTotal(t.Bytes), WithStats(Bytes)}, opts...)...)
+	if err != nil {
+		return CopyReport{}, err
+	}
+
+	report := CopyReport{Errors: append([]FileError{}, t.Errors...)}
+	for _, e := range t.Errors {
+		r.WarnMsg(e)
+	}
+
+	var dirs []TreeEntry // permissions are set last, so read-only directories can be filled
+	files := 0
+	for _, e := range t.Entries {
+		if err := ctx.Err(); err != nil {
+			r.Finish(err)
+			return report, err
+		}
+
+		src := filepath.Join(t.Root, filepath.FromSlash(e.Path))
+		target := filepath.Join(dst, filepath.FromSlash(e.Path))
+		switch {
+		case e.Mode.IsDir():
+			err = os.MkdirAll(target, 0o700)
+			if err == nil {
+				report.Dirs++
+				dirs = append(dirs, e)
+			}
+		case e.Mode&fs.ModeSymlink != 0:
+			err = copyLink(src, target)
+			if err == nil {
+				report.Links++
+			}
+		case e.Mode.IsRegular():
+			files++
+			r.SetLabel(r.locale.FormatInt(int64(files)) + "/" + r.locale.FormatInt(int64(t.Files)) + " " + e.Path)
+			var n int64
+			n, err = copyFile(ctx, r, src, target, e.Mode)
+			if err == nil {
+				report.Files++
+				report.Bytes += n
+			} else {
+				// Count the rest of the file so the bar still ends at 100%
+				r.Add(max(e.Size-n, 0))
+			}
+		default:
+			err = fmt.Errorf("unsupported file type %s", e.Mode.Type())
+		}
+
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				r.Finish(ctxErr)
+				return report, ctxErr
+			}
+			fe := fileError(e.Path, err)
+			report.Errors = append(report.Errors, fe)
+			r.WarnMsg(fe)
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target := filepath.Join(dst, filepath.FromSlash(dirs[i].Path))
+		if err := os.Chmod(target, dirs[i].Mode.Perm()); err != nil {
+			fe := fileError(dirs[i].Path, err)
+			report.Errors = append(report.Errors, fe)
+			r.WarnMsg(fe)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		err = fmt.Errorf("%d of %d entries failed", len(report.Errors), len(t.Entries)+len(t.Errors))
+		r.Finish(err)
+		return report, err
+	}
+	r.Finish(nil)
+	return report, nil
+}
+
+// copyFile copies a regular file and adds the bytes written to the bar.
+// A partly written file is removed.
+func copyFile(ctx context.Context, r *Ravan, src, dst string, mode fs.FileMode) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(&barWriter{ctx: ctx, w: out, r: r}, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(dst, mode.Perm())
+	}
+	if err != nil {
+		os.Remove(dst)
+		return n, err
+	}
+
+	if info, err := in.Stat(); err == nil {
+		os.Chtimes(dst, info.ModTime(), info.ModTime())
+	}
+	return n, nil
+}
+
+// copyLink creates a symlink with the target of the symlink src.
+func copyLink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
+
+// barWriter adds the bytes written to w to the bar and stops when ctx is done.
+type barWriter struct {
+	ctx context.Context
+	w   io.Writer
+	r   *Ravan
+}
+
+func (b *barWriter) Write(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := b.w.Write(p)
+	b.r.Add(int64(n))
+	return n, err
+}
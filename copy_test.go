@@ -0,0 +1,195 @@
+package ravan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// makeTree creates a small tree with files, a directory and a symlink.
+func makeTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"a.txt":       "hello",
+		"docs/b.txt":  strings.Repeat("b", 1000),
+		"docs/run.sh": "#!/bin/sh\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(root, "docs/run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "docs"), 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(root, "docs"), 0o755) })
+	if err := os.Symlink("docs/b.txt", filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	return root
+}
+
+func TestScanTree(t *testing.T) {
+	tree, err := ScanTree(makeTree(t))
+	if err != nil {
+		t.Fatalf("ScanTree() error: %v", err)
+	}
+
+	if tree.Files != 3 || tree.Bytes != 1015 {
+		t.Errorf("ScanTree() = %d files, %d bytes; want 3 files, 1015 bytes", tree.Files, tree.Bytes)
+	}
+	var paths []string
+	for _, e := range tree.Entries {
+		paths = append(paths, e.Path)
+	}
+	if got, want := strings.Join(paths, " "), ". a.txt docs docs/b.txt docs/run.sh link"; got != want {
+		t.Errorf("entries = %s; want %s", got, want)
+	}
+
+	if _, err := ScanTree(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing root")
+	}
+}
+
+// TestCopyTree verifies contents, permissions and links are copied with
+// the bar counting every byte.
+func TestCopyTree(t *testing.T) {
+	src := makeTree(t)
+	dst := filepath.Join(t.TempDir(), "copy")
+	term := ravantest.NewTerminal(80)
+
+	report, err := CopyTree(context.Background(), src, dst,
+		WithWriter(term), WithWidth(10), WithClock(ravantest.NewClock(time.Now())))
+	t.Cleanup(func() { os.Chmod(filepath.Join(dst, "docs"), 0o755) })
+	if err != nil {
+		t.Fatalf("CopyTree() error: %v", err)
+	}
+
+	if report.Files != 3 || report.Bytes != 1015 || report.Dirs != 2 || report.Links != 1 || len(report.Errors) != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "docs/b.txt")); len(data) != 1000 {
+		t.Errorf("docs/b.txt has %d bytes; want 1000", len(data))
+	}
+	if info, _ := os.Stat(filepath.Join(dst, "docs/run.sh")); info == nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("run.sh must keep its permissions, got %v", info)
+	}
+	if info, _ := os.Stat(filepath.Join(dst, "docs")); info == nil || info.Mode().Perm() != 0o555 {
+		t.Errorf("docs must keep its permissions, got %v", info)
+	}
+	if target, err := os.Readlink(filepath.Join(dst, "link")); err != nil || target != "docs/b.txt" {
+		t.Errorf("link = %q, %v; want a symlink to docs/b.txt", target, err)
+	}
+
+	want := "3/3 docs/run.sh [==========] 100% 1015 B/1015 B 0 B/s\nSuccess: Operation successful"
+	if got := term.String(); got != want {
+		t.Errorf("screen:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestCopyTreeManyFiles verifies the label of every file doesn't redraw
+// the bar more often than the frame rate allows.
+func TestCopyTreeManyFiles(t *testing.T) {
+	src := t.TempDir()
+	for i := 0; i < 300; i++ {
+		if err := os.WriteFile(filepath.Join(src, fmt.Sprintf("%03d.txt", i)), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := &countingWriter{}
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	start := time.Now()
+	report, err := CopyTree(context.Background(), src, t.TempDir(), WithWriter(w), WithClock(clock), WithWidth(10))
+	if err != nil || report.Files != 300 {
+		t.Fatalf("CopyTree() = %+v, %v; want 300 files", report, err)
+	}
+	// The clock stands still, so only the first frame, the frames of the
+	// frame timer, the complete frame and the message are written
+	limit := 3 + int(time.Since(start)/(time.Second/defaultMaxFPS)) + 1
+	if got := w.count(); got > limit {
+		t.Errorf("expected at most %d writes for 300 files, got %d", limit, got)
+	}
+}
+
+// TestCopyTreeErrors verifies a failing file is reported and the rest is copied.
+func TestCopyTreeErrors(t *testing.T) {
+	src := makeTree(t)
+	dst := t.TempDir()
+	// A directory where the file should go makes it fail
+	if err := os.MkdirAll(filepath.Join(dst, "a.txt"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(dst, "docs"), 0o755) })
+	term := ravantest.NewTerminal(80)
+
+	report, err := CopyTree(context.Background(), src, dst, WithWriter(term), WithWidth(10))
+	if err == nil || err.Error() != "1 of 6 entries failed" {
+		t.Fatalf("CopyTree() error = %v; want 1 of 6 entries failed", err)
+	}
+	if report.Files != 2 || len(report.Errors) != 1 || report.Errors[0].Path != "a.txt" {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	got := term.String()
+	if !strings.Contains(got, "Warning: a.txt: is a directory. Operation completed with warnings") {
+		t.Errorf("expected a warning for a.txt, got\n%s", got)
+	}
+	if !strings.Contains(got, "3/3 docs/run.sh [==========] 100% 1015 B/1015 B") ||
+		!strings.HasSuffix(got, "Error: 1 of 6 entries failed. Operation failed (1 warning)") {
+		t.Errorf("expected a full bar and a failure, got\n%s", got)
+	}
+}
+
+func TestCopyTreeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CopyTree(ctx, makeTree(t), t.TempDir(), WithWriter(ravantest.NewTerminal(80)))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CopyTree() error = %v; want context.Canceled", err)
+	}
+}
+
+// TestCopyTreeCanceledInFile verifies bytes of a removed partial file are
+// not reported as copied.
+func TestCopyTreeCanceledInFile(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "big.bin"), make([]byte, 1<<20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelOnBytes := MsgRenderer(func(msg interface{}) {
+		if p, ok := msg.(ProgressMsg); ok && p.Current > 0 {
+			cancel()
+		}
+	})
+
+	report, err := CopyTree(ctx, src, dst, WithRenderer(cancelOnBytes))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("CopyTree() error = %v; want context.Canceled", err)
+	}
+	if report.Files != 0 || report.Bytes != 0 {
+		t.Errorf("report = %+v; want no files and bytes copied", report)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "big.bin")); !os.IsNotExist(err) {
+		t.Errorf("partial file must be removed, got %v", err)
+	}
+}
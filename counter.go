@@ -0,0 +1,87 @@
+package ravan
+
+import (
+	"time"
+)
+
+// Counter is the lock-free item counter of a bar for CPU-bound hot loops.
+// Add never draws and never takes a lock; the bar is drawn from the counter
+// by the goroutine started with Start.
+type Counter struct {
+	r *Ravan
+}
+
+// Counter returns the lock-free counter of the bar. It shares the count
+// with Add and Increment.
+func (r *Ravan) Counter() *Counter {
+	return &Counter{r: r}
+}
+
+// Add adds n items with a single atomic operation.
+func (c *Counter) Add(n int64) {
+	c.r.current.Add(n)
+}
+
+// Increment adds a single item.
+func (c *Counter) Increment() {
+	c.r.current.Add(1)
+}
+
+// Load returns the current count.
+func (c *Counter) Load() int64 {
+	return c.r.current.Load()
+}
+
+// Start runs a goroutine that draws the bar from its counter at the frame
+// rate set with WithMaxFPS (30 frames per second when unlimited) until the
+// bar is finished. Calling Start again has no effect.
+func (r *Ravan) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != Running || r.ticker != nil {
+		return
+	}
+
+	fps := r.maxFPS
+	if fps == 0 {
+		fps = defaultMaxFPS
+	}
+	r.ticker = time.NewTicker(time.Second / time.Duration(fps))
+	r.tickerDone = make(chan struct{})
+	go r.tick(r.ticker, r.tickerDone)
+}
+
+// tick draws the bar on every tick while the count changes.
+func (r *Ravan) tick(ticker *time.Ticker, done chan struct{}) {
+	drawn := int64(-1)
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		if r.status != Running {
+			r.mu.Unlock()
+			return
+		}
+		if current := r.current.Load(); current != drawn {
+			drawn = current
+			r.updateProgress()
+			r.flush()
+		}
+		r.mu.Unlock()
+	}
+}
+
+// stopTicker stops the goroutine started with Start. r.mu must be held.
+func (r *Ravan) stopTicker() {
+	if r.ticker == nil {
+		return
+	}
+	r.ticker.Stop()
+	close(r.tickerDone)
+	r.ticker = nil
+}
@@ -0,0 +1,96 @@
+package ravan
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCounter verifies the counter is shared with the bar and drawn by Start.
+func TestCounter(t *testing.T) {
+	w := &countingWriter{}
+	r, err := New(WithWriter(w), WithWidth(10), WithTotal(1000), WithMaxFPS(100))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	c := r.Counter()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				c.Increment()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := c.Load(); got != 400 {
+		t.Fatalf("Load() = %d; want 400", got)
+	}
+	if got := w.count(); got != 0 {
+		t.Fatalf("Counter must not draw, got %d writes", got)
+	}
+
+	r.Start()
+	r.Start() // second call has no effect
+	deadline := time.Now().Add(time.Second)
+	for w.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.HasSuffix(w.buf.String(), "] 40%") {
+		t.Fatalf("expected the render goroutine to draw 40%%, got %q", w.buf.String())
+	}
+
+	c.Add(100)
+	res := r.Finish(nil)
+	if res.Current != 500 {
+		t.Errorf("Result.Current = %d; want 500", res.Current)
+	}
+	if !strings.Contains(w.buf.String(), "] 50%\n") {
+		t.Errorf("expected Finish to draw the last count, got %q", w.buf.String())
+	}
+}
+
+// BenchmarkCounterAdd measures the lock-free counter of a running bar.
+func BenchmarkCounterAdd(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard), WithTotal(int64(b.N)+1))
+	c := r.Counter()
+	r.Start()
+	defer r.Finish(nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Increment()
+	}
+}
+
+// BenchmarkCounterAddParallel measures the counter from many goroutines.
+func BenchmarkCounterAddParallel(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard), WithTotal(int64(b.N)+1))
+	c := r.Counter()
+	r.Start()
+	defer r.Finish(nil)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Increment()
+		}
+	})
+}
+
+// BenchmarkDrawPerItem measures the classic usage of calling Draw for every item.
+func BenchmarkDrawPerItem(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard))
+	total := float64(b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Draw(float64(i+1) / total)
+	}
+}
@@ -0,0 +1,246 @@
+package ravan
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DownloadOption configures Download. Every bar Option is one, so they
+// can be passed together with WithChecksum.
+type DownloadOption interface {
+	applyDownload(d *downloadConfig) error
+}
+
+// downloadConfig is the configuration of a Download.
+type downloadConfig struct {
+	bar      []Option
+	checksum *checksum
+}
+
+// checksum is the expected hash of a download, see WithChecksum.
+type checksum struct {
+	new func() hash.Hash
+	sum []byte
+}
+
+func (o Option) applyDownload(d *downloadConfig) error {
+	d.bar = append(d.bar, o)
+	return nil
+}
+
+// downloadOption is a DownloadOption that isn't a bar Option.
+type downloadOption func(d *downloadConfig) error
+
+func (o downloadOption) applyDownload(d *downloadConfig) error {
+	return o(d)
+}
+
+// WithChecksum makes Download verify the file against a hex-encoded sum,
+// e.g. WithChecksum(sha256.New, "9f86d08...").
+func WithChecksum(newHash func() hash.Hash, sum string) DownloadOption {
+	return downloadOption(func(d *downloadConfig) error {
+		b, err := hex.DecodeString(sum)
+		if err != nil || newHash == nil || len(b) != newHash().Size() {
+			return fmt.Errorf("invalid checksum: %s", sum)
+		}
+		d.checksum = &checksum{new: newHash, sum: b}
+		return nil
+	})
+}
+
+// Download fetches url into dst with a bar of the bytes received, using
+// Content-Length as total and WithStats(Bytes) for rate and ETA; bar
+// options in opts follow those options. A nil client uses http.DefaultClient.
+//
+// The data is written to dst+".part" and renamed to dst when complete.
+// A part file left by an interrupted download is resumed with a Range
+// request, or started over if the server doesn't support ranges.
+// With WithChecksum the file is verified before it is renamed and a
+// file that doesn't match is removed.
+//
+// Errors finish the bar with the error, printed like FailMsg, and are returned.
+func Download(ctx context.Context, client *http.Client, url, dst string, opts ...DownloadOption) error {
+	d := downloadConfig{bar: []Option{WithStats(Bytes)}}
+	for _, opt := range opts {
+		if err := opt.applyDownload(&d); err != nil {
+			return err
+		}
+	}
+	r, err := New(d.bar...)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	err = r.download(ctx, client, url, dst, d.checksum)
+	r.Finish(err)
+	return err
+}
+
+// download does the work of Download without finishing the bar.
+func (r *Ravan) download(ctx context.Context, client *http.Client, url, dst string, sum *checksum) error {
+	part := dst + ".part"
+	var offset int64
+	if info, err := os.Stat(part); err == nil && info.Mode().IsRegular() {
+		offset = info.Size()
+	}
+
+	resp, err := get(ctx, client, url, offset)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+		if start != offset {
+			return fmt.Errorf("server resumed at byte %d instead of %d", start, offset)
+		}
+		total = size
+		if total < 0 && resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case http.StatusOK:
+		offset = 0 // ranges not supported, start over
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The part file is complete or doesn't belong to this file anymore
+		_, size, _ := parseContentRange(resp.Header.Get("Content-Range"))
+		if size != offset {
+			os.Remove(part)
+			resp.Body.Close()
+			return r.download(ctx, client, url, dst, sum)
+		}
+		total = size
+	default:
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset > 0 {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var h hash.Hash
+	var w io.Writer = f
+	if sum != nil {
+		h = sum.new()
+		if offset > 0 {
+			if err := hashFile(h, part); err != nil {
+				return err
+			}
+		}
+		w = io.MultiWriter(f, h)
+	}
+
+	r.begin(total, offset)
+	if total < 0 {
+		r.Start() // Add doesn't draw without a total
+	}
+	var n int64
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		n, err = io.Copy(&barWriter{ctx: ctx, w: w, r: r}, resp.Body)
+		if err != nil {
+			return err
+		}
+	}
+	if total >= 0 && offset+n != total {
+		return fmt.Errorf("download incomplete: got %d of %d bytes: %w", offset+n, total, io.ErrUnexpectedEOF)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if h != nil {
+		if got := h.Sum(nil); !bytes.Equal(got, sum.sum) {
+			os.Remove(part)
+			return fmt.Errorf("checksum mismatch: got %x, want %x", got, sum.sum)
+		}
+	}
+	return os.Rename(part, dst)
+}
+
+// get requests url, asking for the bytes from offset on if it is not 0.
+func get(ctx context.Context, client *http.Client, url string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return client.Do(req)
+}
+
+// parseContentRange returns the first byte and the complete size of a
+// Content-Range header such as "bytes 100-999/1000" or "bytes */1000".
+// The size is -1 if unknown.
+func parseContentRange(header string) (start, size int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	rng, sizeText, found := strings.Cut(spec, "/")
+	if !ok || !found {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+
+	size = -1
+	if sizeText != "*" {
+		if size, err = strconv.ParseInt(sizeText, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range: %q", header)
+		}
+	}
+	if rng == "*" {
+		return 0, size, nil
+	}
+	first, _, _ := strings.Cut(rng, "-")
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", header)
+	}
+	return start, size, nil
+}
+
+// hashFile writes the contents of a file to h.
+func hashFile(h hash.Hash, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// begin sets the total, which is unknown below 0, and the items done
+// before the bar started, and draws the bar.
+func (r *Ravan) begin(total, done int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if total > 0 {
+		r.total = total
+	}
+	r.current.Store(done)
+	r.resumed = done
+	r.updateProgress()
+	r.update(true)
+}
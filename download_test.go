@@ -0,0 +1,174 @@
+package ravan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// downloadServer serves content with Range support and records the
+// Range header of every request.
+func downloadServer(t *testing.T, content []byte) (*httptest.Server, *[]string) {
+	t.Helper()
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ranges = append(ranges, req.Header.Get("Range"))
+		http.ServeContent(w, req, "data.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &ranges
+}
+
+func sha256Hex(b []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("ravan"), 1000)
+	srv, _ := downloadServer(t, content)
+	dst := filepath.Join(t.TempDir(), "data.bin")
+	term := ravantest.NewTerminal(80)
+
+	err := Download(context.Background(), srv.Client(), srv.URL, dst,
+		WithWriter(term), WithWidth(10), WithChecksum(sha256.New, sha256Hex(content)),
+		WithClock(ravantest.NewClock(time.Now())))
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes; want the %d bytes served", len(got), len(content))
+	}
+	if _, err := os.Stat(dst + ".part"); !os.IsNotExist(err) {
+		t.Errorf("part file must be renamed, got %v", err)
+	}
+	want := "[==========] 100% 4.9 KiB/4.9 KiB 0 B/s\nSuccess: Operation successful"
+	if got := term.String(); got != want {
+		t.Errorf("screen:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestDownloadResume verifies a part file is continued with a Range request.
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	srv, ranges := downloadServer(t, content)
+	dst := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(dst+".part", content[:400], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+
+	err := Download(context.Background(), srv.Client(), srv.URL, dst,
+		WithWriter(&out), WithWidth(10), WithChecksum(sha256.New, sha256Hex(content)))
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, content) {
+		t.Errorf("resumed file differs from the content served")
+	}
+	if len(*ranges) != 1 || (*ranges)[0] != "bytes=400-" {
+		t.Errorf("requests with ranges %q; want one request for bytes=400-", *ranges)
+	}
+	if got := out.String(); !strings.Contains(got, "] 40%") {
+		t.Errorf("expected the bar to start at 40%%, got %q", got)
+	}
+}
+
+// TestDownloadWithoutRanges verifies a server ignoring Range starts over.
+func TestDownloadWithoutRanges(t *testing.T) {
+	content := []byte("fresh content")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write(content)
+	}))
+	defer srv.Close()
+	dst := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(dst+".part", []byte("stale"), 0o644)
+
+	err := Download(context.Background(), nil, srv.URL, dst, WithWriter(ravantest.NewTerminal(80)))
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, content) {
+		t.Errorf("downloaded %q; want %q", got, content)
+	}
+}
+
+func TestDownloadErrors(t *testing.T) {
+	content := []byte("payload")
+	srv, _ := downloadServer(t, content)
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		opts []DownloadOption
+		want string
+	}{
+		{"checksum", srv.URL, []DownloadOption{WithChecksum(sha256.New, sha256Hex([]byte("other")))}, "checksum mismatch"},
+		{"status", notFound.URL, nil, "GET " + notFound.URL + ": 404 Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "data.bin")
+			term := ravantest.NewTerminal(200)
+
+			err := Download(context.Background(), nil, tt.url, dst, append(tt.opts, WithWriter(term))...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Download() error = %v; want %q", err, tt.want)
+			}
+			if !strings.Contains(term.String(), "Error: "+err.Error()+". Operation failed") {
+				t.Errorf("expected the error as failure message, got\n%s", term.String())
+			}
+			for _, path := range []string{dst, dst + ".part"} {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("%s must not exist after a failure", filepath.Base(path))
+				}
+			}
+		})
+	}
+}
+
+// TestWithChecksum verifies a checksum is checked before the request.
+func TestWithChecksum(t *testing.T) {
+	tests := []DownloadOption{WithChecksum(sha256.New, "xyz"), WithChecksum(sha256.New, "abcd"), WithChecksum(nil, sha256Hex(nil))}
+	for i, opt := range tests {
+		err := Download(context.Background(), nil, "http://invalid.test/", filepath.Join(t.TempDir(), "x"), opt, WithWriter(io.Discard))
+		if err == nil || !strings.Contains(err.Error(), "invalid checksum") {
+			t.Errorf("test %d: Download() error = %v; want an invalid checksum", i, err)
+		}
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header      string
+		start, size int64
+	}{
+		{"bytes 100-999/1000", 100, 1000},
+		{"bytes 0-9/*", 0, -1},
+		{"bytes */42", 0, 42},
+	}
+	for _, tt := range tests {
+		start, size, err := parseContentRange(tt.header)
+		if err != nil || start != tt.start || size != tt.size {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v; want %d, %d", tt.header, start, size, err, tt.start, tt.size)
+		}
+	}
+	if _, _, err := parseContentRange("items 1-2/3"); err == nil {
+		t.Error("expected an error for a unit other than bytes")
+	}
+}
@@ -0,0 +1,108 @@
+package ravan
+
+import (
+	"math"
+	"time"
+)
+
+// defaultMaxFPS is the redraw limit used unless WithMaxFPS is set.
+const defaultMaxFPS = 30
+
+// frameState coalesces redraws to at most maxFPS frames per second.
+type frameState struct {
+	last    time.Time   // time of the last drawn frame
+	pending bool        // an update was skipped and waits for the next frame
+	drawn   int64       // count shown by the last drawn frame
+	told    time.Time   // time watchers were last told the count, see notifyCount
+	timer   *time.Timer // draws the pending update when the frame is due
+}
+
+// update draws the bar if a frame is due, otherwise it schedules the
+// latest state for the next frame. Forced updates, the first and the
+// complete frame are always drawn. r.mu must be held.
+func (r *Ravan) update(force bool) {
+	r.scheduleNextDraw()
+
+	if force || !r.started || r.maxFPS == 0 || r.progress >= 1.0 {
+		r.flush()
+		return
+	}
+
+	interval := time.Second / time.Duration(r.maxFPS)
+	wait := interval - r.now().Sub(r.frame.last)
+	if wait <= 0 {
+		r.flush()
+		return
+	}
+
+	r.frame.pending = true
+	if r.frame.timer == nil {
+		r.frame.timer = time.AfterFunc(wait, r.drawPending)
+	}
+}
+
+// scheduleNextDraw sets the count at which Add has to take the slow path
+// again: the next 0.1% of the total, but not beyond the total so the
+// complete frame is drawn. Without a total only watchers need the slow
+// path, on every change. r.mu must be held.
+func (r *Ravan) scheduleNextDraw() {
+	current := r.current.Load()
+	if r.total <= 0 {
+		if len(r.watchers) > 0 {
+			r.nextDraw.Store(current + 1)
+		} else {
+			r.nextDraw.Store(math.MaxInt64)
+		}
+		return
+	}
+	next := current + max(r.total/1000, 1)
+	if current < r.total {
+		next = min(next, r.total)
+	}
+	r.nextDraw.Store(next)
+}
+
+// notifyCount tells the watchers the count of a bar without a total,
+// which Add doesn't draw, at most maxFPS times per second. r.mu must be held.
+func (r *Ravan) notifyCount() {
+	now := r.now()
+	if r.maxFPS > 0 && now.Sub(r.frame.told) < time.Second/time.Duration(r.maxFPS) {
+		return
+	}
+	r.frame.told = now
+	r.notify(r.state())
+}
+
+// flush draws the current state right away. r.mu must be held.
+func (r *Ravan) flush() {
+	r.stopFrames()
+	r.frame.last = r.now()
+	r.frame.drawn = r.current.Load()
+	r.draw()
+}
+
+// flushPending draws a skipped update before other output. r.mu must be held.
+func (r *Ravan) flushPending() {
+	if r.frame.pending && r.status == Running {
+		r.flush()
+	}
+}
+
+// drawPending is run by the frame timer.
+func (r *Ravan) drawPending() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.frame.timer = nil
+	r.updateProgress()
+	r.flushPending()
+}
+
+// stopFrames cancels a scheduled frame. r.mu must be held.
+func (r *Ravan) stopFrames() {
+	r.frame.pending = false
+	if r.frame.timer != nil {
+		r.frame.timer.Stop()
+		r.frame.timer = nil
+	}
+}
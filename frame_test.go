@@ -0,0 +1,202 @@
+package ravan
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// countingWriter counts the writes that reach the terminal.
+type countingWriter struct {
+	mu     sync.Mutex
+	writes int
+	buf    bytes.Buffer
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+// TestMaxFPSCoalesces verifies updates inside a frame are coalesced into the next frame.
+func TestMaxFPSCoalesces(t *testing.T) {
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := &countingWriter{}
+	r, err := New(WithWriter(w), WithClock(clock), WithTotal(100), WithMaxFPS(10))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	r.Add(10) // first frame is drawn right away
+	for i := 0; i < 20; i++ {
+		r.Add(1) // inside the same 100ms frame
+	}
+	if got := w.count(); got != 1 {
+		t.Fatalf("expected 1 write inside the frame, got %d", got)
+	}
+
+	clock.Advance(100 * time.Millisecond)
+	r.Add(1)
+	if got := w.count(); got != 2 {
+		t.Fatalf("expected a redraw once the frame is due, got %d writes", got)
+	}
+	if !strings.HasSuffix(w.buf.String(), "] 31%") {
+		t.Errorf("expected the coalesced state to be drawn, got %q", w.buf.String())
+	}
+
+	// A skipped update is drawn by the frame timer without further calls.
+	r.Add(1)
+	deadline := time.Now().Add(time.Second)
+	for w.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := w.count(); got != 3 {
+		t.Fatalf("expected pending frame to be drawn, got %d writes", got)
+	}
+
+	r.Add(1)
+	r.Finish(nil)
+	if !strings.Contains(w.buf.String(), "] 33%\n") {
+		t.Errorf("expected Finish to draw the pending frame, got %q", w.buf.String())
+	}
+}
+
+// TestSkipUnchangedLine verifies updates that don't change the line are not written.
+func TestSkipUnchangedLine(t *testing.T) {
+	w := &countingWriter{}
+	r, _ := New(WithWriter(w), WithWidth(10), WithTotal(1000000), WithMaxFPS(0))
+
+	for i := 0; i < 1000; i++ {
+		r.Increment()
+	}
+	if got := w.count(); got != 1 {
+		t.Errorf("expected a single write for an unchanged line, got %d", got)
+	}
+}
+
+// TestAddFastPath verifies Add skips the lock until the next visible change.
+func TestAddFastPath(t *testing.T) {
+	r, _ := New(WithWriter(io.Discard), WithTotal(10000))
+
+	r.Increment()
+	if next := r.nextDraw.Load(); next != 11 {
+		t.Fatalf("nextDraw = %d; want 11", next)
+	}
+
+	// Holding the lock proves the fast path does not need it.
+	r.mu.Lock()
+	for i := 0; i < 5; i++ {
+		r.Increment()
+	}
+	r.mu.Unlock()
+
+	if s := r.Snapshot(); s.Current != 6 || s.Fraction != 0.0006 {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+}
+
+// TestCompleteFrame verifies a bar counted up to its total ends as a full
+// green line even when the 0.1% steps of Add don't hit the total.
+func TestCompleteFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int64
+		opts  []Option
+		tick  time.Duration
+	}{
+		{"default fps", 2000, nil, 100 * time.Millisecond},
+		{"unlimited", 1_000_000, []Option{WithMaxFPS(0)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			term := ravantest.NewTerminal(80)
+			opts := append([]Option{WithWriter(term), WithClock(clock), WithWidth(10), WithTotal(tt.total)}, tt.opts...)
+			r, _ := New(opts...)
+
+			for i := int64(0); i < tt.total; i++ {
+				clock.Advance(tt.tick)
+				r.Increment()
+			}
+			r.Finish(nil)
+
+			want := "<32>[==========] 100%</>\n<32>Success: Operation successful</>"
+			if got := term.StyledString(); got != want {
+				t.Errorf("screen:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestFinishDrawsCount verifies Finish shows a count that Add didn't draw.
+func TestFinishDrawsCount(t *testing.T) {
+	term := ravantest.NewTerminal(80)
+	r, _ := New(WithWriter(term), WithWidth(10), WithTotal(2000), WithMaxFPS(0))
+
+	r.Add(1000)
+	r.Counter().Add(500) // never draws
+	r.Finish(errors.New("stopped"))
+
+	if got := term.Lines()[0]; got != "[=======   ] 75%" {
+		t.Errorf("bar line = %q; want the count at 75%%", got)
+	}
+}
+
+func TestWithMaxFPSNegative(t *testing.T) {
+	if _, err := New(WithMaxFPS(-1)); err == nil {
+		t.Error("expected error for negative max FPS")
+	}
+}
+
+// BenchmarkIncrement measures the hot path of Add between visible changes.
+func BenchmarkIncrement(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard), WithTotal(int64(b.N)+1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Increment()
+	}
+}
+
+// BenchmarkIncrementParallel measures Add from many goroutines.
+func BenchmarkIncrementParallel(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard), WithTotal(int64(b.N)+1))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.Increment()
+		}
+	})
+}
+
+// BenchmarkDraw measures Draw called for every item, which always takes the lock.
+func BenchmarkDraw(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Draw(float64(i) / float64(b.N))
+	}
+}
+
+// BenchmarkDrawUnlimited measures Draw without frame limiting.
+func BenchmarkDrawUnlimited(b *testing.B) {
+	r, _ := New(WithWriter(io.Discard), WithMaxFPS(0))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Draw(float64(i) / float64(b.N))
+	}
+}
@@ -0,0 +1,102 @@
+package ravan
+
+import (
+	"encoding/json"
+)
+
+// jsonRenderer writes newline-delimited JSON events for tools wrapping a CLI.
+// Durations are written in seconds.
+type jsonRenderer struct {
+	r *Ravan
+}
+
+type jsonStartEvent struct {
+	Event string `json:"event"`
+	Total int64  `json:"total"`
+}
+
+type jsonProgressEvent struct {
+	Event    string  `json:"event"`
+	Current  int64   `json:"current"`
+	Total    int64   `json:"total"`
+	Fraction float64 `json:"fraction"`
+	Rate     float64 `json:"rate"`
+	ETA      float64 `json:"eta"`
+	Elapsed  float64 `json:"elapsed"`
+	Note     string  `json:"note,omitempty"`
+}
+
+type jsonMessageEvent struct {
+	Event   string `json:"event"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type jsonFinishEvent struct {
+	Event    string  `json:"event"`
+	Status   string  `json:"status"`
+	Current  int64   `json:"current"`
+	Total    int64   `json:"total"`
+	Fraction float64 `json:"fraction"`
+	Elapsed  float64 `json:"elapsed"`
+	Warnings int     `json:"warnings"`
+	Retries  int     `json:"retries"`
+	Requeued int64   `json:"requeued"`
+	Error    string  `json:"error,omitempty"`
+}
+
+func (j *jsonRenderer) Start(s Snapshot) {
+	j.write(jsonStartEvent{Event: "start", Total: s.Total})
+}
+
+func (j *jsonRenderer) Progress(s Snapshot) {
+	j.write(jsonProgressEvent{
+		Event:    "progress",
+		Current:  s.Current,
+		Total:    s.Total,
+		Fraction: s.Fraction,
+		Rate:     s.Rate,
+		ETA:      s.ETA.Seconds(),
+		Elapsed:  s.Elapsed.Seconds(),
+		Note:     s.Note,
+	})
+}
+
+func (j *jsonRenderer) Message(s Snapshot, kind MessageKind, err error, text string) {
+	j.write(jsonMessageEvent{
+		Event:   "message",
+		Kind:    kind.String(),
+		Message: text,
+		Error:   errorString(err),
+	})
+}
+
+func (j *jsonRenderer) Finish(s Snapshot) {
+	j.write(jsonFinishEvent{
+		Event:    "finish",
+		Status:   s.Status.String(),
+		Current:  s.Current,
+		Total:    s.Total,
+		Fraction: s.Fraction,
+		Elapsed:  s.Elapsed.Seconds(),
+		Warnings: s.Warnings,
+		Retries:  s.Retries,
+		Requeued: s.Requeued,
+		Error:    errorString(s.Err),
+	})
+}
+
+// write encodes a single event on its own line.
+// Write errors are ignored like failed terminal writes.
+func (j *jsonRenderer) write(event interface{}) {
+	json.NewEncoder(j.r.stdout()).Encode(event)
+}
+
+// errorString returns the error text or an empty string for nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,106 @@
+package ravan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// decodeEvents splits newline-delimited JSON output into generic events.
+func decodeEvents(t *testing.T, output string) []map[string]interface{} {
+	t.Helper()
+
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// TestJSONOutput verifies the event stream of a failed run.
+func TestJSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New(WithOutput(JSONOutput), WithWriter(&buf), WithTotal(4))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	r.Add(2)
+	r.WarnMsg("slow mirror")
+	r.Finish(errors.New("boom"))
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("JSON output must not contain escape codes: %q", buf.String())
+	}
+
+	events := decodeEvents(t, buf.String())
+	var names []string
+	for _, e := range events {
+		names = append(names, e["event"].(string))
+	}
+	want := "start progress message message finish"
+	if got := strings.Join(names, " "); got != want {
+		t.Fatalf("events = %q; want %q", got, want)
+	}
+
+	progress := events[1]
+	if progress["current"] != 2.0 || progress["total"] != 4.0 || progress["fraction"] != 0.5 {
+		t.Errorf("unexpected progress event %v", progress)
+	}
+	if _, ok := progress["eta"]; !ok {
+		t.Errorf("progress event has no eta: %v", progress)
+	}
+
+	warn := events[2]
+	if warn["kind"] != "warn" || warn["message"] != "slow mirror" {
+		t.Errorf("unexpected warn event %v", warn)
+	}
+
+	failed := events[3]
+	if failed["kind"] != "failed" || failed["error"] != "boom" {
+		t.Errorf("unexpected failed event %v", failed)
+	}
+
+	finish := events[4]
+	if finish["status"] != "failed" || finish["error"] != "boom" || finish["warnings"] != 1.0 {
+		t.Errorf("unexpected finish event %v", finish)
+	}
+}
+
+// TestJSONOutputEnv verifies RAVAN_OUTPUT selects the mode and options take precedence.
+func TestJSONOutputEnv(t *testing.T) {
+	t.Setenv("RAVAN_OUTPUT", "json")
+
+	var buf bytes.Buffer
+	r, err := New(WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	r.Finish(nil)
+	if !strings.HasPrefix(buf.String(), `{"event":"start"`) {
+		t.Errorf("expected JSON output from environment, got %q", buf.String())
+	}
+
+	buf.Reset()
+	r, err = New(WithWriter(&buf), WithOutput(TerminalOutput))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	r.Finish(nil)
+	if strings.HasPrefix(buf.String(), "{") {
+		t.Errorf("expected WithOutput to override the environment, got %q", buf.String())
+	}
+}
+
+func TestWithOutputInvalid(t *testing.T) {
+	_, err := New(WithOutput("xml"))
+	if err == nil || !contains(err.Error(), "invalid output mode") {
+		t.Errorf("expected invalid output mode error, got %v", err)
+	}
+}
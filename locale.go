@@ -0,0 +1,190 @@
+package ravan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale controls the direction of the bar, how numbers are written and
+// the language of the built-in strings.
+// The zero value is the default left-to-right English locale with Western
+// digits and no thousands separator.
+type Locale struct {
+	Digits    [10]rune // digits 0-9, Western digits when unset
+	Percent   string   // percent sign, "%" when unset
+	Thousands string   // thousands separator, none when unset
+	Decimal   string   // decimal mark, "." when unset
+	RTL       bool     // fill the bar right-to-left and isolate message text
+	Catalog   Catalog  // built-in strings, English when unset
+}
+
+var (
+	// DefaultLocale writes left-to-right with Western digits.
+	DefaultLocale = Locale{}
+
+	// EnglishLocale is DefaultLocale with thousands separators, e.g. "1,234".
+	EnglishLocale = Locale{
+		Thousands: ",",
+		Decimal:   ".",
+		Catalog:   englishCatalog,
+	}
+
+	// GermanLocale writes German with German separators, e.g. "1.234,5".
+	GermanLocale = Locale{
+		Thousands: ".",
+		Decimal:   ",",
+		Catalog:   germanCatalog,
+	}
+
+	// PersianLocale writes Persian right-to-left with Persian digits, e.g. "۴۵٪".
+	PersianLocale = Locale{
+		Digits:    [10]rune{'۰', '۱', '۲', '۳', '۴', '۵', '۶', '۷', '۸', '۹'},
+		Percent:   "٪",
+		Thousands: "٬",
+		Decimal:   "٫",
+		RTL:       true,
+		Catalog:   persianCatalog,
+	}
+
+	// ArabicLocale writes Arabic right-to-left with Arabic-Indic digits, e.g. "٤٥٪".
+	ArabicLocale = Locale{
+		Digits:    [10]rune{'٠', '١', '٢', '٣', '٤', '٥', '٦', '٧', '٨', '٩'},
+		Percent:   "٪",
+		Thousands: "٬",
+		Decimal:   "٫",
+		RTL:       true,
+		Catalog:   arabicCatalog,
+	}
+)
+
+// Unicode bidi isolation marks
+const (
+	rightToLeftIsolate    = "\u2067"
+	firstStrongIsolate    = "\u2068"
+	popDirectionalIsolate = "\u2069"
+)
+
+// WithLocale sets the direction of the bar, the digits and separators used
+// for percentages and counts and the language of the built-in strings.
+// It overrides the LANG environment variable, see WithLanguage.
+func WithLocale(l Locale) Option {
+	return func(r *Ravan) error {
+		r.locale = l
+		return nil
+	}
+}
+
+// localizeDigits replaces the Western digits in s with the digits of the locale.
+func (l Locale) localizeDigits(s string) string {
+	if l.Digits == ([10]rune{}) {
+		return s
+	}
+	return strings.Map(func(c rune) rune {
+		if c >= '0' && c <= '9' {
+			return l.Digits[c-'0']
+		}
+		return c
+	}, s)
+}
+
+// percent formats a progress between 0.0 and 1.0 as a whole percentage.
+func (l Locale) percent(progress float64) string {
+	sign := l.Percent
+	if sign == "" {
+		sign = "%"
+	}
+	return l.localizeDigits(fmt.Sprintf("%.0f", progress*100)) + sign
+}
+
+// isolate wraps message text in a right-to-left isolate for RTL locales,
+// so it is not reordered with the surrounding prefix and numbers.
+func (l Locale) isolate(text string) string {
+	if !l.RTL || text == "" {
+		return text
+	}
+	return rightToLeftIsolate + text + popDirectionalIsolate
+}
+
+// isolateError wraps an error, which may be in any language, in a
+// first-strong isolate for RTL locales.
+func (l Locale) isolateError(err error) string {
+	if !l.RTL {
+		return err.Error()
+	}
+	return firstStrongIsolate + err.Error() + popDirectionalIsolate
+}
+
+// FormatInt writes n with the digits and thousands separator of the locale.
+func (l Locale) FormatInt(n int64) string {
+	return l.localizeDigits(l.group(strconv.FormatInt(n, 10)))
+}
+
+// FormatFloat writes f with prec decimals, the decimal mark and the
+// thousands separator of the locale.
+func (l Locale) FormatFloat(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	whole, frac, found := strings.Cut(s, ".")
+	s = l.group(whole)
+	if found {
+		mark := l.Decimal
+		if mark == "" {
+			mark = "."
+		}
+		s += mark + frac
+	}
+	return l.localizeDigits(s)
+}
+
+// FormatDuration writes d rounded to seconds in the units of the locale,
+// e.g. "1h2m3s" or "۲ دقیقه و ۵ ثانیه".
+func (l Locale) FormatDuration(d time.Duration) string {
+	c := l.catalog()
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+
+	h, m, s := int64(d/time.Hour), int64(d%time.Hour/time.Minute), int64(d%time.Minute/time.Second)
+	var parts []string
+	if h > 0 {
+		parts = append(parts, fmt.Sprintf(c.Hours, l.FormatInt(h)))
+	}
+	if m > 0 {
+		parts = append(parts, fmt.Sprintf(c.Minutes, l.FormatInt(m)))
+	}
+	if s > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf(c.Seconds, l.FormatInt(s)))
+	}
+	return strings.Join(parts, c.UnitSep)
+}
+
+// group inserts the thousands separator into a string of Western digits.
+func (l Locale) group(digits string) string {
+	if l.Thousands == "" {
+		return digits
+	}
+
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(l.Thousands)
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// plural formats n with the one or many form of a catalog entry.
+func (l Locale) plural(n int64, forms [2]string) string {
+	form := forms[1]
+	if n == 1 {
+		form = forms[0]
+	}
+	return fmt.Sprintf(form, l.FormatInt(n))
+}
@@ -0,0 +1,68 @@
+package ravan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// TestPersianLocale verifies the bar fills right-to-left with Persian digits.
+func TestPersianLocale(t *testing.T) {
+	r, err := New(WithWidth(10), WithTotal(4), WithLocale(PersianLocale), WithTheme(Theme{}))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	r.current.Add(1)
+	if got, want := r.Render(80), "[        ==] ۲۵٪"; got != want {
+		t.Errorf("Render() = %q; want %q", got, want)
+	}
+
+	r.current.Add(3)
+	if got, want := r.Render(80), "[==========] ۱۰۰٪"; got != want {
+		t.Errorf("Render() = %q; want %q", got, want)
+	}
+}
+
+func TestArabicLocalePercent(t *testing.T) {
+	if got, want := ArabicLocale.percent(0.45), "٤٥٪"; got != want {
+		t.Errorf("percent(0.45) = %q; want %q", got, want)
+	}
+	if got, want := DefaultLocale.percent(0.45), "45%"; got != want {
+		t.Errorf("percent(0.45) = %q; want %q", got, want)
+	}
+}
+
+// TestLocaleMessages verifies message text is isolated and prefixes and counts are localized.
+func TestLocaleMessages(t *testing.T) {
+	term := ravantest.NewTerminal(80)
+	r, _ := New(WithWriter(term), WithLocale(PersianLocale), WithTheme(Theme{}),
+		WithMessage(&Message{Failed: "عملیات ناموفق بود"}))
+
+	r.WarnMsg("فایل رد شد")
+	r.WarnMsg("فایل رد شد")
+	r.Finish(errors.New("disk full"))
+
+	lines := strings.Split(term.String(), "\n")
+	if want := "هشدار: \u2067فایل رد شد\u2069"; lines[0] != want {
+		t.Errorf("warning = %q; want %q", lines[0], want)
+	}
+	want := "خطا: \u2068disk full\u2069. \u2067عملیات ناموفق بود\u2069 (۲ هشدار)"
+	if got := lines[len(lines)-1]; got != want {
+		t.Errorf("failure = %q; want %q", got, want)
+	}
+}
+
+// TestLocaleRetryNote verifies the width of a localized note is counted in runes.
+func TestLocaleRetryNote(t *testing.T) {
+	term := ravantest.NewTerminal(40)
+	r, _ := New(WithWriter(term), WithLocale(PersianLocale), WithTotal(2))
+
+	r.Increment()
+	r.Retry(2, 5, 0)
+	if got, want := term.String(), "[         ========] ۵۰٪ تلاش دوباره ۲/۵"; got != want {
+		t.Errorf("screen = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package ravan
+
+import (
+	"expvar"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// expvarBar is the state of a bar published with Registry.Expvar.
+type expvarBar struct {
+	Current  int64   `json:"current"`
+	Total    int64   `json:"total"`
+	Fraction float64 `json:"fraction"`
+	Rate     float64 `json:"rate"`
+	Status   string  `json:"status"`
+}
+
+// Expvar returns an expvar variable with the state of every bar by
+// name, read whenever /debug/vars is served:
+//
+//	expvar.Publish("ravan", reg.Expvar())
+//
+// gives {"ravan": {"import": {"current": 42, "total": 100, "fraction": 0.42, "rate": 3.5, "status": "running"}}}.
+func (g *Registry) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		bars := make(map[string]expvarBar)
+		for name, s := range g.Snapshots() {
+			bars[name] = expvarBar{
+				Current:  s.Current,
+				Total:    s.Total,
+				Fraction: s.Fraction,
+				Rate:     s.Rate,
+				Status:   s.Status.String(),
+			}
+		}
+		return bars
+	})
+}
+
+// metric is a gauge of the Prometheus exposition written for every bar.
+type metric struct {
+	name  string
+	help  string
+	value func(s Snapshot) float64
+}
+
+var metrics = []metric{
+	{"ravan_current", "Items done by the bar.", func(s Snapshot) float64 { return float64(s.Current) }},
+	{"ravan_total", "Items the bar counts up to, 0 if unknown.", func(s Snapshot) float64 { return float64(s.Total) }},
+	{"ravan_fraction", "Progress of the bar between 0 and 1.", func(s Snapshot) float64 { return s.Fraction }},
+	{"ravan_rate", "Items per second since the bar started.", func(s Snapshot) float64 { return s.Rate }},
+	{"ravan_elapsed_seconds", "Seconds since the bar started.", func(s Snapshot) float64 { return s.Elapsed.Seconds() }},
+}
+
+// statuses are the values of the status label of ravan_status.
+var statuses = []Status{Running, Succeeded, Failed, Aborted}
+
+// MetricsHandler serves the state of every bar in the Prometheus text
+// exposition format, without the Prometheus client library:
+//
+//	ravan_current{bar="import"} 42
+//	ravan_total{bar="import"} 100
+//	ravan_status{bar="import",status="running"} 1
+//
+// ravan_status has a series for every status with 1 for the current one.
+func (g *Registry) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(g.exposition()))
+	})
+}
+
+// exposition returns the metrics of all bars in the text exposition format.
+func (g *Registry) exposition() string {
+	snaps := g.Snapshots()
+	names := make([]string, 0, len(snaps))
+	for name := range snaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{bar=\"%s\"} %s\n", m.name, escapeLabel(name), formatMetric(m.value(snaps[name])))
+		}
+	}
+
+	b.WriteString("# HELP ravan_status Status of the bar, 1 for the current status.\n# TYPE ravan_status gauge\n")
+	for _, name := range names {
+		for _, status := range statuses {
+			value := 0
+			if snaps[name].Status == status {
+				value = 1
+			}
+			fmt.Fprintf(&b, "ravan_status{bar=\"%s\",status=\"%s\"} %d\n", escapeLabel(name), status, value)
+		}
+	}
+	return b.String()
+}
+
+// escapeLabel escapes a label value of the text exposition format.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatMetric writes a sample value, whole numbers without exponent.
+func formatMetric(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,63 @@
+package ravan
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// metricsRegistry returns a registry with a running and a failed bar.
+func metricsRegistry() *Registry {
+	clock := ravantest.NewClock(time.Now())
+	reg := NewRegistry()
+
+	imp, _ := New(WithTotal(1000000), WithWriter(io.Discard), WithClock(clock))
+	imp.Add(250000)
+	reg.Add("import", imp)
+
+	syncBar, _ := New(WithWriter(io.Discard), WithClock(clock))
+	reg.Add(`s3 "eu"`, syncBar)
+	clock.Advance(2 * time.Second)
+	syncBar.Finish(io.ErrUnexpectedEOF)
+	return reg
+}
+
+func TestMetricsHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	metricsRegistry().MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	ravantest.AssertGolden(t, "metrics", rec.Body.String())
+}
+
+func TestExpvar(t *testing.T) {
+	var got map[string]expvarBar
+	if err := json.Unmarshal([]byte(metricsRegistry().Expvar().String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	want := expvarBar{Current: 250000, Total: 1000000, Fraction: 0.25, Rate: 125000, Status: "running"}
+	if got["import"] != want {
+		t.Errorf("import = %+v; want %+v", got["import"], want)
+	}
+	if got[`s3 "eu"`].Status != "failed" {
+		t.Errorf(`s3 "eu" = %+v; want failed`, got[`s3 "eu"`])
+	}
+}
+
+func TestFormatMetric(t *testing.T) {
+	tests := map[float64]string{0: "0", 1000000: "1000000", 0.25: "0.25", 1e20: "1e+20"}
+	for v, want := range tests {
+		if got := formatMetric(v); got != want {
+			t.Errorf("formatMetric(%v) = %q; want %q", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ravan
+
+// ProgressMsg carries a progress change into the Update loop of an
+// Elm-architecture TUI such as Bubble Tea.
+type ProgressMsg Snapshot
+
+// NoticeMsg carries a message of the bar (FailMsg, WarnMsg, ...) into an Update loop.
+type NoticeMsg struct {
+	Snapshot Snapshot
+	Kind     MessageKind
+	Err      error
+	Text     string
+}
+
+// MsgRenderer is a Renderer that turns bar updates into ProgressMsg and
+// NoticeMsg values, e.g. WithRenderer(ravan.MsgRenderer(program.Send)).
+// send is called with the bar's lock held.
+type MsgRenderer func(msg interface{})
+
+func (m MsgRenderer) Start(s Snapshot)    { m(ProgressMsg(s)) }
+func (m MsgRenderer) Progress(s Snapshot) { m(ProgressMsg(s)) }
+func (m MsgRenderer) Finish(s Snapshot)   { m(ProgressMsg(s)) }
+
+func (m MsgRenderer) Message(s Snapshot, kind MessageKind, err error, text string) {
+	m(NoticeMsg{Snapshot: s, Kind: kind, Err: err, Text: text})
+}
+
+// Model is a view-model of a Ravan bar for string-composing layouts.
+// It does no I/O: Update stores progress changes and View returns the
+// bar line for Width columns.
+type Model struct {
+	Width int // columns available for the bar line, 0 uses the bar width
+
+	style *Ravan
+	snap  Snapshot
+}
+
+// NewModel creates a Model styled with the same options as New.
+func NewModel(opts ...Option) (Model, error) {
+	r, err := New(opts...)
+	if err != nil {
+		return Model{}, err
+	}
+	return Model{style: r, snap: Snapshot{Total: r.total, Label: r.label}}, nil
+}
+
+// Update applies ProgressMsg and NoticeMsg values and ignores all other messages.
+func (m Model) Update(msg interface{}) Model {
+	switch v := msg.(type) {
+	case ProgressMsg:
+		m.snap = Snapshot(v)
+	case NoticeMsg:
+		m.snap = v.Snapshot
+	}
+	return m
+}
+
+// View returns the bar line without carriage return or newline.
+func (m Model) View() string {
+	style := m.style
+	if style == nil {
+		style, _ = New() // zero Model uses the default style
+	}
+	return style.renderLine(m.snap, m.Width)
+}
+
+// Message returns the line printed for a NoticeMsg, e.g. "Info: copying",
+// in the color of its kind, without newline.
+func (m Model) Message(n NoticeMsg) string {
+	style := m.style
+	if style == nil {
+		style, _ = New()
+	}
+	return style.formatMessage(n.Snapshot, n.Kind, n.Err, n.Text)
+}
+
+// Snapshot returns the state last applied with Update.
+func (m Model) Snapshot() Snapshot {
+	return m.snap
+}
@@ -0,0 +1,83 @@
+package ravan
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestModelView verifies the view-model renders for the given width without I/O.
+func TestModelView(t *testing.T) {
+	m, err := NewModel(WithWidth(10), WithCompleteChar(Hash), WithTheme(Theme{}))
+	if err != nil {
+		t.Fatalf("NewModel() error: %v", err)
+	}
+
+	output := captureOutput(func() {
+		m = m.Update(ProgressMsg{Current: 5, Total: 10, Fraction: 0.5})
+		m.Width = 40
+	})
+	if output != "" {
+		t.Errorf("Model must not write output, got %q", output)
+	}
+
+	want := "[#####     ] 50%"
+	if got := m.View(); got != want {
+		t.Errorf("View() = %q; want %q", got, want)
+	}
+
+	// A narrow layout shrinks the bar.
+	m.Width = 12
+	want = "[##   ] 50%"
+	if got := m.View(); got != want {
+		t.Errorf("View() at width 12 = %q; want %q", got, want)
+	}
+
+	m = m.Update("unrelated message")
+	if m.Snapshot().Current != 5 {
+		t.Errorf("unrelated messages must be ignored, got %+v", m.Snapshot())
+	}
+}
+
+// TestMsgRenderer verifies bar updates arrive as messages for an Update loop.
+func TestMsgRenderer(t *testing.T) {
+	var msgs []interface{}
+	r, err := New(WithTotal(4), WithRenderer(MsgRenderer(func(msg interface{}) {
+		msgs = append(msgs, msg)
+	})))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	m, _ := NewModel(WithWidth(8), WithTheme(Theme{}))
+	r.Add(3)
+	r.WarnMsg("slow")
+	for _, msg := range msgs {
+		m = m.Update(msg)
+	}
+
+	if len(msgs) != 3 {
+		t.Fatalf("expected start, progress and notice messages, got %d", len(msgs))
+	}
+	if n, ok := msgs[2].(NoticeMsg); !ok || n.Kind != WarnMessage || n.Text != "slow" {
+		t.Errorf("unexpected notice message %#v", msgs[2])
+	}
+	if got := m.View(); !strings.HasSuffix(got, "] 75%") {
+		t.Errorf("View() = %q; want 75%%", got)
+	}
+}
+
+func TestZeroModelView(t *testing.T) {
+	var m Model
+	if got := m.View(); !strings.HasSuffix(got, "] 0%") {
+		t.Errorf("zero Model View() = %q", got)
+	}
+}
+
+// TestModelMessage verifies notices are formatted like the terminal prints them.
+func TestModelMessage(t *testing.T) {
+	m, _ := NewModel(WithTheme(Theme{Info: "\033[36m"}))
+	got := m.Message(NoticeMsg{Kind: InfoMessage, Text: "copying"})
+	if want := "\033[36mInfo: copying\033[0m"; got != want {
+		t.Errorf("Message() = %q; want %q", got, want)
+	}
+}
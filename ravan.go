@@ -3,8 +3,11 @@ package ravan
 import (
 	"fmt"
 	"golang.org/x/term"
+	"io"
 	"os"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type BarCharacter string
@@ -56,10 +59,8 @@ var (
 		And: true, AtSign: true, Percent: true, CircumFlex: true,
 	}
 
-	// colors for messagees
-	successColor = "\033[32m"
-	errorColor   = "\033[31m"
-	resetColor   = "\033[0m"
+	// resets colors set by the theme
+	resetColor = "\033[0m"
 )
 
 // Option pattern for configuration
@@ -69,12 +70,26 @@ var (
 //	WithCompleteChar
 //	WithIncompleteChar
 //	WithMessage
+//	WithTotal
+//	WithTheme
+//	WithWriter
+//	WithOutput
+//	WithRenderer
+//	WithLabel
+//	WithClock
+//	WithMaxFPS
+//	WithLocale
+//	WithLanguage
+//	WithStats
+//	WithCheckpoint
 type Option func(*Ravan) error
 
 // Message struct for showing with Ravan progress bar
 type Message struct {
 	Failed  string
 	Success string
+	Warn    string
+	Info    string
 }
 
 // Ravan struct
@@ -83,6 +98,40 @@ type Ravan struct {
 	completeChar   BarCharacter
 	incompleteChar BarCharacter
 	message        Message
+	theme          *Theme
+	locale         Locale
+	clock          Clock
+	writer         io.Writer
+	mode           OutputMode
+	maxFPS         int
+	stats          bool          // show count, rate and ETA, see WithStats
+	units          Units         // units of the stats
+	checkpoint     *checkpointer // see WithCheckpoint
+
+	// current is updated without the lock; the slow path under the lock
+	// only runs once it reaches nextDraw, see Add.
+	current  atomic.Int64
+	nextDraw atomic.Int64
+
+	mu       sync.Mutex
+	out      Renderer
+	started  bool
+	label    string
+	total    int64
+	progress float64
+	frame    frameState
+
+	ticker     *time.Ticker // draws from the Counter, see Start
+	tickerDone chan struct{}
+	start      time.Time
+	status     Status
+	result     Result
+	warnings   int
+	retry      *retryState
+	retries    int
+	requeued   int64
+	resumed    int64      // items done before the bar started, not part of rate and ETA
+	watchers   []*watcher // see Watch
 }
 
 // New creates a validated Ravan instance
@@ -91,10 +140,17 @@ func New(opts ...Option) (*Ravan, error) {
 		width:          50,    // Default width
 		completeChar:   Equal, // Default complete
 		incompleteChar: Empty, // Default incomplete
-		message: Message{
-			Failed:  "Operation failed",
-			Success: "Operation successful",
-		}, // Default message
+		mode:           TerminalOutput,
+		maxFPS:         defaultMaxFPS,
+	}
+
+	// The environment picks the output mode unless an option overrides it
+	if mode := OutputMode(os.Getenv("RAVAN_OUTPUT")); isValidOutput(mode) {
+		r.mode = mode
+	}
+	// So do LC_ALL, LC_MESSAGES and LANG for the language
+	if l, ok := envLocale(); ok {
+		r.locale = l
 	}
 
 	for _, opt := range opts {
@@ -107,38 +163,191 @@ func New(opts ...Option) (*Ravan, error) {
 		return nil, fmt.Errorf("complete and incomplete characters must differ")
 	}
 
+	// Messages not set with WithMessage come from the locale's catalog
+	r.message = r.message.withDefaults(r.locale.catalog().Messages)
+	r.start = r.now()
+
+	if r.out == nil {
+		switch r.mode {
+		case JSONOutput:
+			r.out = &jsonRenderer{r: r}
+		case AccessibleOutput:
+			r.out = &accessibleRenderer{r: r}
+		}
+	}
+	if r.checkpoint != nil {
+		r.watchers = append(r.watchers, &watcher{notify: r.saveCheckpoint})
+	}
+
 	return r, nil
 }
 
 // Draw renders the progress bar on the terminal.
 // progress should be a value between 0.0 and 1.0.
+// The line printed is the one returned by Render for the terminal width.
 // When progress is 1.0 (100%), the bar is printed in green.
+// Redraws are limited by WithMaxFPS; a skipped frame is drawn shortly after.
+// Draw does nothing once the bar has been finished with Finish.
 func (r *Ravan) Draw(progress float64) {
-	termWidth := getTerminalWidth()
-	if termWidth == 0 {
-		termWidth = r.width // fallback if terminal width cannot be determined
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != Running {
+		return
 	}
 
-	// Overhead accounts for extra characters like "[", "]", " 100%"
-	overhead := 7
-	effectiveWidth := r.width
-	if termWidth-overhead < effectiveWidth {
-		effectiveWidth = termWidth - overhead
-		if effectiveWidth < 1 {
-			effectiveWidth = 1
-		}
+	changed := r.retry != nil
+	r.stopRetry()
+	r.progress = progress
+	if r.total > 0 {
+		r.current.Store(int64(progress * float64(r.total)))
+	}
+	r.update(changed)
+}
+
+// Add advances the bar by n items and redraws it.
+// The progress is calculated against the total set by WithTotal;
+// without a total only the count is recorded.
+//
+// Add is safe for concurrent use. It only takes the bar's lock when the
+// count reaches the next visible change of the bar, otherwise it costs a
+// single atomic addition.
+func (r *Ravan) Add(n int64) {
+	if r.current.Add(n) < r.nextDraw.Load() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != Running {
+		return
+	}
+
+	changed := r.retry != nil
+	r.stopRetry()
+	if r.total <= 0 && !changed {
+		// Nothing to draw without a total, only watchers to tell
+		r.notifyCount()
+		r.scheduleNextDraw()
+		return
+	}
+
+	r.updateProgress()
+	r.update(changed)
+}
+
+// updateProgress recalculates progress from the item count. r.mu must be held.
+func (r *Ravan) updateProgress() {
+	if r.total <= 0 {
+		return
+	}
+	r.progress = float64(r.current.Load()) / float64(r.total)
+	if r.progress > 1.0 {
+		r.progress = 1.0
 	}
+}
+
+// Increment advances the bar by a single item.
+func (r *Ravan) Increment() {
+	r.Add(1)
+}
+
+// Render returns the bar line for the current state in width columns,
+// exactly as Draw prints it but without carriage return, newline or any I/O.
+// A width of 0 uses the width set with WithWidth.
+func (r *Ravan) Render(width int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.renderLine(r.state(), width)
+}
 
-	complete := int(progress * float64(effectiveWidth))
-	bar := strings.Repeat(string(r.completeChar), complete) +
-		strings.Repeat(string(r.incompleteChar), effectiveWidth-complete)
+// draw hands the current state to the renderer. r.mu must be held.
+func (r *Ravan) draw() {
+	r.output().Progress(r.state())
+}
 
-	if progress >= 1.0 {
-		// Print in green when complete
-		fmt.Printf("\r\033[32m[%s] %.0f%%\033[0m\n", bar, progress*100)
-	} else {
-		fmt.Printf("\r[%s] %.0f%%", bar, progress*100)
+// output returns the renderer of the bar and announces the start of the
+// run on first use. r.mu must be held.
+func (r *Ravan) output() Renderer {
+	if r.out == nil {
+		r.out = &terminalRenderer{r: r}
+	}
+	out := r.out
+	if len(r.watchers) > 0 {
+		out = watchedRenderer{r}
+	}
+	if !r.started {
+		r.started = true
+		out.Start(r.state())
 	}
+	return out
+}
+
+// Snapshot returns the current state of the bar.
+func (r *Ravan) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state()
+}
+
+// SetLabel changes the text shown in front of the bar and redraws it,
+// limited by WithMaxFPS like Add.
+func (r *Ravan) SetLabel(label string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.label = label
+	if r.status == Running && r.started {
+		r.update(false)
+	}
+}
+
+// state captures the current state of the bar. r.mu must be held.
+func (r *Ravan) state() Snapshot {
+	if r.start.IsZero() {
+		r.start = r.now()
+	}
+
+	current := r.current.Load()
+	if r.status != Running {
+		// Updates after Finish are not part of the result
+		current = r.result.Current
+	} else if r.total > 0 && int64(r.progress*float64(r.total)) != current {
+		// Add changed the count without taking the lock
+		r.updateProgress()
+	}
+
+	s := Snapshot{
+		Current:  current,
+		Total:    r.total,
+		Fraction: r.progress,
+		Elapsed:  r.now().Sub(r.start),
+		Status:   r.status,
+		Label:    r.label,
+		Retry:    r.retryInfo(),
+		Warnings: r.warnings,
+		Retries:  r.retries,
+		Requeued: r.requeued,
+		Err:      r.result.Err,
+	}
+
+	s.Note = DefaultLocale.retryNote(s.Retry)
+
+	if s.Elapsed > 0 {
+		s.Rate = float64(s.Current-r.resumed) / s.Elapsed.Seconds()
+	}
+	if s.Fraction > 0 && s.Fraction < 1 {
+		done := s.Fraction
+		if r.total > 0 {
+			done -= float64(r.resumed) / float64(r.total)
+		}
+		if done > 0 {
+			s.ETA = time.Duration(float64(s.Elapsed) * (1 - s.Fraction) / done)
+		}
+	}
+
+	return s
 }
 
 // FailMsg shows error (if provided) and/or custom failure message
@@ -147,11 +356,57 @@ func (r *Ravan) Draw(progress float64) {
 // r.FailMsg(err)                   // Shows error + custom message
 // r.FailMsg(err, customMessage)    // Optional: Override default custom message
 func (r *Ravan) FailMsg(err ...interface{}) {
+	e, customMsg := parseMsgArgs(r.message.Failed, err)
+	r.emit(FailedMessage, e, customMsg)
+}
+
+// SuccessMsg prints a success message with the theme's success color.
+// Warnings and retries during the run are counted in the message.
+func (r *Ravan) SuccessMsg() {
+	r.emit(SuccessMessage, nil, r.message.Success)
+}
+
+// WarnMsg shows a non-fatal warning and counts it for the final summary.
+// It accepts the same arguments as FailMsg:
+// r.WarnMsg()                      // Shows the Warn message
+// r.WarnMsg(err)                   // Shows error + Warn message
+// r.WarnMsg("3 files skipped")     // Overrides the Warn message
+func (r *Ravan) WarnMsg(args ...interface{}) {
+	e, customMsg := parseMsgArgs(r.message.Warn, args)
+	r.emit(WarnMessage, e, customMsg)
+}
+
+// InfoMsg shows an intermediate notice without affecting the outcome.
+// It accepts the same arguments as FailMsg.
+func (r *Ravan) InfoMsg(args ...interface{}) {
+	e, customMsg := parseMsgArgs(r.message.Info, args)
+	r.emit(InfoMessage, e, customMsg)
+}
+
+// Log prints a line of text above the bar, such as the output of a child
+// process, and keeps the bar below it. Unlike InfoMsg it has no prefix or color.
+func (r *Ravan) Log(text string) {
+	r.emit(LogMessage, nil, text)
+}
+
+// emit hands a message to the renderer.
+func (r *Ravan) emit(kind MessageKind, e error, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if kind == WarnMessage {
+		r.warnings++
+	}
+	r.flushPending()
+	r.output().Message(r.state(), kind, e, text)
+}
+
+// parseMsgArgs picks the error and message override out of message arguments.
+func parseMsgArgs(defaultMsg string, args []interface{}) (error, string) {
 	var e error
-	customMsg := r.message.Failed // Default to initialized message
+	customMsg := defaultMsg // Default to initialized message
 
-	// Parse arguments
-	for _, arg := range err {
+	for _, arg := range args {
 		switch v := arg.(type) {
 		case error:
 			e = v
@@ -160,30 +415,94 @@ func (r *Ravan) FailMsg(err ...interface{}) {
 		}
 	}
 
-	msg := strings.Builder{}
-	msg.WriteString("\n" + errorColor)
+	return e, customMsg
+}
 
-	if e != nil {
-		msg.WriteString(fmt.Sprintf("Error: %v. ", e))
+// Width option
+func WithWidth(w int) Option {
+	return func(r *Ravan) error {
+		r.width = w
+		return nil
 	}
+}
 
-	if customMsg != "" {
-		msg.WriteString(customMsg)
+// WithTotal sets the number of items the bar counts up to with Add and Increment.
+func WithTotal(total int64) Option {
+	return func(r *Ravan) error {
+		if total < 0 {
+			return fmt.Errorf("total must not be negative: %d", total)
+		}
+		r.total = total
+		return nil
 	}
+}
 
-	msg.WriteString(resetColor + "\n")
-	fmt.Print(msg.String())
+// WithWriter sets where the bar is written to. Defaults to os.Stdout.
+// The terminal width is taken from w if it is a terminal file or
+// has a Width() int method, like the fake terminal in ravantest.
+func WithWriter(w io.Writer) Option {
+	return func(r *Ravan) error {
+		if w == nil {
+			return fmt.Errorf("writer must not be nil")
+		}
+		r.writer = w
+		return nil
+	}
 }
 
-// SuccessMsg prints a success message with successColor.
-func (r *Ravan) SuccessMsg() {
-	fmt.Printf("%sSuccess: %s%s\n", successColor, r.message.Success, resetColor)
+// WithOutput sets how the bar is rendered, see OutputMode.
+// It takes precedence over the RAVAN_OUTPUT environment variable.
+func WithOutput(mode OutputMode) Option {
+	return func(r *Ravan) error {
+		if !isValidOutput(mode) {
+			return fmt.Errorf("invalid output mode: %s", mode)
+		}
+		r.mode = mode
+		return nil
+	}
 }
 
-// Width option
-func WithWidth(w int) Option {
+// WithRenderer replaces the built-in output with a custom Renderer,
+// e.g. a TUI widget or a status line. It takes precedence over WithOutput.
+func WithRenderer(rd Renderer) Option {
 	return func(r *Ravan) error {
-		r.width = w
+		if rd == nil {
+			return fmt.Errorf("renderer must not be nil")
+		}
+		r.out = rd
+		return nil
+	}
+}
+
+// WithLabel sets the text shown in front of the bar.
+func WithLabel(label string) Option {
+	return func(r *Ravan) error {
+		r.label = label
+		return nil
+	}
+}
+
+// WithClock sets the clock used for elapsed time, rate, ETA and retry countdowns.
+// Tests can inject a fake clock, see ravantest.Clock.
+func WithClock(c Clock) Option {
+	return func(r *Ravan) error {
+		if c == nil {
+			return fmt.Errorf("clock must not be nil")
+		}
+		r.clock = c
+		return nil
+	}
+}
+
+// WithMaxFPS limits how many times per second the bar is redrawn.
+// Updates in between are coalesced into the next frame. 0 means no limit.
+// Defaults to 30.
+func WithMaxFPS(fps int) Option {
+	return func(r *Ravan) error {
+		if fps < 0 {
+			return fmt.Errorf("max FPS must not be negative: %d", fps)
+		}
+		r.maxFPS = fps
 		return nil
 	}
 }
@@ -219,6 +538,12 @@ func WithMessage(msg *Message) Option {
 		if msg.Success != "" {
 			r.message.Success = msg.Success
 		}
+		if msg.Warn != "" {
+			r.message.Warn = msg.Warn
+		}
+		if msg.Info != "" {
+			r.message.Info = msg.Info
+		}
 		return nil
 	}
 }
@@ -235,10 +560,49 @@ func isValidCharacter(c BarCharacter, charType string) bool {
 	}
 }
 
+// Clock tells Ravan the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// sizer is implemented by writers that know their terminal width.
+type sizer interface {
+	Width() int
+}
+
+// now returns the time of the bar's clock.
+func (r *Ravan) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+// stdout returns the writer of the bar.
+func (r *Ravan) stdout() io.Writer {
+	if r.writer == nil {
+		return os.Stdout
+	}
+	return r.writer
+}
+
 // getTerminalWidth returns the number of columns in the terminal.
 // If an error occurs, it returns 0.
 func getTerminalWidth() int {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	return widthOf(os.Stdout)
+}
+
+// widthOf returns the number of columns of w if it is a terminal, otherwise 0.
+func widthOf(w io.Writer) int {
+	if s, ok := w.(sizer); ok {
+		return s.Width()
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
 	if err != nil {
 		return 0
 	}
@@ -2,14 +2,29 @@ package ravan
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/pooulad/ravan/ravantest"
 )
 
+// TestMain clears the environment that changes the output of New and LoadConfig,
+// so the tests don't depend on the language or output mode of the machine.
+func TestMain(m *testing.M) {
+	for _, key := range []string{ConfigEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		os.Unsetenv(key)
+	}
+	for _, key := range configKeys {
+		os.Unsetenv(configEnvName(key))
+	}
+	os.Exit(m.Run())
+}
+
 // Helper function to capture the output of a function
 func captureOutput(f func()) string {
 	// Create a pipe to read and write data
@@ -369,3 +384,170 @@ func TestWithMessageOptionPartial(t *testing.T) {
 		t.Errorf("Expected default Success message %q, got %q", expectedSuccess, r.message.Success)
 	}
 }
+
+// TestWarnAndInfoMsg verifies warnings and notices are printed and warnings are counted.
+func TestWarnAndInfoMsg(t *testing.T) {
+	r, err := New(WithMessage(&Message{Warn: "custom warning"}))
+	if err != nil {
+		t.Fatalf("New(WithMessage) error: %v", err)
+	}
+
+	output := captureOutput(func() {
+		r.WarnMsg()
+	})
+	want := DefaultTheme.Warn + "Warning: custom warning" + resetColor + "\n"
+	if output != want {
+		t.Errorf("WarnMsg() = %q; want %q", output, want)
+	}
+
+	output = captureOutput(func() {
+		r.WarnMsg(errors.New("disk slow"), "3 files skipped")
+	})
+	want = DefaultTheme.Warn + "Warning: disk slow. 3 files skipped" + resetColor + "\n"
+	if output != want {
+		t.Errorf("WarnMsg(err, msg) = %q; want %q", output, want)
+	}
+
+	output = captureOutput(func() {
+		r.InfoMsg("halfway there")
+	})
+	want = DefaultTheme.Info + "Info: halfway there" + resetColor + "\n"
+	if output != want {
+		t.Errorf("InfoMsg() = %q; want %q", output, want)
+	}
+
+	output = captureOutput(func() {
+		r.SuccessMsg()
+	})
+	want = DefaultTheme.Success + "Success: Operation successful (2 warnings)" + resetColor + "\n"
+	if output != want {
+		t.Errorf("SuccessMsg() = %q; want %q", output, want)
+	}
+
+	var res Result
+	captureOutput(func() {
+		res = r.Finish(nil)
+	})
+	if res.Warnings != 2 {
+		t.Errorf("Result.Warnings = %d; want 2", res.Warnings)
+	}
+}
+
+// TestWarnMsgBreaksBarLine verifies a notice does not overwrite an unfinished bar.
+func TestWarnMsgBreaksBarLine(t *testing.T) {
+	r, _ := New(WithWidth(10), WithTheme(Theme{}))
+	output := captureOutput(func() {
+		r.Draw(0.5)
+		r.WarnMsg("slow")
+	})
+	if !strings.HasSuffix(output, "%\nWarning: slow\n") {
+		t.Errorf("expected warning on its own line, got %q", output)
+	}
+}
+
+// TestLog verifies logged lines replace the bar and the bar is drawn again below them.
+func TestLog(t *testing.T) {
+	term := ravantest.NewTerminal(40)
+	r, _ := New(WithWriter(term), WithWidth(10), WithTotal(4), WithTheme(Theme{}))
+
+	r.Log("before the bar")
+	r.Add(2)
+	r.Log("ok")
+	r.Log("migrating table users")
+
+	want := strings.Join([]string{
+		"before the bar",
+		"ok",
+		"migrating table users",
+		"[=====     ] 50%",
+	}, "\n")
+	if got := term.String(); got != want {
+		t.Errorf("screen =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestRender verifies Render returns the exact bar line without writing output.
+func TestRender(t *testing.T) {
+	r, err := New(WithWidth(10), WithTotal(4))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		add   int64
+		width int
+		want  string
+	}{
+		{"empty bar", 0, 0, "[   ] 0%"},
+		{"half in wide terminal", 2, 80, "[=====     ] 50%"},
+		{"half in narrow terminal", 0, 13, "[===   ] 50%"},
+		{"tiny terminal keeps one cell", 0, 3, "[ ] 50%"},
+		{"complete", 2, 80, DefaultTheme.Success + "[==========] 100%" + resetColor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.mu.Lock()
+			r.current.Add(tt.add)
+			r.updateProgress()
+			r.mu.Unlock()
+
+			var got string
+			output := captureOutput(func() {
+				got = r.Render(tt.width)
+			})
+			if output != "" {
+				t.Errorf("Render must not write output, got %q", output)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%d) = %q; want %q", tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDrawUsesRender verifies Draw prints the line returned by Render.
+func TestDrawUsesRender(t *testing.T) {
+	var buf bytes.Buffer
+	r, _ := New(WithWriter(&buf), WithWidth(20))
+
+	r.Draw(0.25)
+	if want := "\r" + r.Render(0); buf.String() != want {
+		t.Errorf("Draw(0.25) = %q; want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	r.Draw(1.0)
+	if want := "\r" + r.Render(0) + "\n"; buf.String() != want {
+		t.Errorf("Draw(1.0) = %q; want %q", buf.String(), want)
+	}
+}
+
+// TestDrawOnTerminal verifies what a user sees after a run, using a fake
+// clock and a virtual terminal instead of sleeps and the real terminal width.
+func TestDrawOnTerminal(t *testing.T) {
+	term := ravantest.NewTerminal(60)
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r, err := New(WithWriter(term), WithClock(clock), WithTotal(4), WithLabel("import"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		clock.Advance(time.Second)
+		r.Increment()
+		if i == 1 {
+			if s := r.Snapshot(); s.Elapsed != 2*time.Second || s.ETA != 2*time.Second || s.Rate != 1 {
+				t.Errorf("unexpected timing in snapshot %+v", s)
+			}
+			r.WarnMsg("slow record")
+		}
+	}
+	res := r.Finish(nil)
+
+	if res.Duration != 4*time.Second {
+		t.Errorf("Result.Duration = %v; want 4s", res.Duration)
+	}
+	ravantest.AssertGolden(t, "draw_on_terminal", term.String())
+}
@@ -0,0 +1,25 @@
+package ravantest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+
+	if !c.Now().Equal(start) {
+		t.Errorf("Now() = %v; want %v", c.Now(), start)
+	}
+
+	c.Advance(90 * time.Second)
+	if got := c.Now().Sub(start); got != 90*time.Second {
+		t.Errorf("after Advance elapsed = %v; want 90s", got)
+	}
+
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("after Set Now() = %v; want %v", c.Now(), start)
+	}
+}
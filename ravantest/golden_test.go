@@ -0,0 +1,35 @@
+package ravantest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssertGolden(t *testing.T) {
+	AssertGolden(t, "sample", "line one\nline two\n")
+}
+
+// TestAssertGoldenUpdate verifies golden files are written when UpdateEnv is set.
+func TestAssertGoldenUpdate(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	t.Setenv(UpdateEnv, "1")
+	AssertGolden(t, "new", "fresh output")
+
+	got, err := os.ReadFile(filepath.Join(dir, "testdata", "new.golden"))
+	if err != nil {
+		t.Fatalf("golden file was not written: %v", err)
+	}
+	if string(got) != "fresh output" {
+		t.Errorf("golden file = %q; want %q", got, "fresh output")
+	}
+}
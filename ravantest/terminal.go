@@ -0,0 +1,426 @@
+package ravantest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Terminal is a minimal VT100 emulator. Writes are interpreted like a real
+// terminal would: carriage returns, backspaces, cursor movement, erase
+// sequences and colors change a screen grid instead of being appended,
+// so tests can assert what a user would actually see.
+//
+// Pass it to ravan.WithWriter; its Width is used as the terminal width.
+type Terminal struct {
+	mu      sync.Mutex
+	width   int
+	height  int // 0 means the screen grows instead of scrolling
+	rows    [][]Cell
+	row     int
+	col     int
+	style   string // SGR parameters applied to printed cells
+	saveRow int
+	saveCol int
+	state   parseState
+	params  []byte
+	pending []byte // incomplete UTF-8 sequence from the previous write
+}
+
+// Cell is a single character on the screen with the SGR parameters
+// (e.g. "32" for green) that were active when it was printed.
+type Cell struct {
+	Rune  rune
+	Style string
+}
+
+type parseState int
+
+const (
+	stateGround parseState = iota
+	stateEscape
+	stateCSI
+)
+
+// NewTerminal creates a virtual terminal with the given number of columns
+// that grows downwards, so no output scrolls out of view.
+func NewTerminal(width int) *Terminal {
+	return NewScreen(width, 0)
+}
+
+// NewScreen creates a virtual terminal with a fixed number of columns and
+// rows. Output below the last row scrolls the screen up like a real terminal.
+// A height of 0 behaves like NewTerminal.
+func NewScreen(width, height int) *Terminal {
+	if width < 1 {
+		width = 1
+	}
+	if height < 0 {
+		height = 0
+	}
+
+	t := &Terminal{width: width, height: height}
+	t.rows = make([][]Cell, max(height, 1))
+	return t
+}
+
+// Width returns the number of columns of the terminal.
+func (t *Terminal) Width() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.width
+}
+
+// Resize changes the size of the screen like a terminal window being resized.
+// Rows longer than the new width are cut off and the cursor, as well as a
+// saved cursor, is kept on screen.
+func (t *Terminal) Resize(width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 0 {
+		height = 0
+	}
+
+	for i, row := range t.rows {
+		if len(row) > width {
+			t.rows[i] = row[:width]
+		}
+	}
+
+	if height > 0 {
+		// Drop rows from the top so the cursor row stays visible
+		for len(t.rows) > height && t.row > 0 {
+			t.rows = t.rows[1:]
+			t.row--
+			t.saveRow--
+		}
+		if len(t.rows) > height {
+			t.rows = t.rows[:height]
+		}
+		for len(t.rows) < height {
+			t.rows = append(t.rows, nil)
+		}
+	}
+
+	t.width = width
+	t.height = height
+	t.col = min(t.col, width-1)
+
+	// A saved cursor moves with its row and stays on screen too
+	t.saveRow = max(t.saveRow, 0)
+	if height > 0 {
+		t.saveRow = min(t.saveRow, height-1)
+	}
+	t.saveCol = min(t.saveCol, width-1)
+}
+
+// Cursor returns the zero based row and column of the cursor.
+func (t *Terminal) Cursor() (row, col int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.row, min(t.col, t.width-1)
+}
+
+// Cell returns the cell at the zero based row and column.
+// Positions that were never written are blank cells.
+func (t *Terminal) Cell(row, col int) Cell {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if row < 0 || row >= len(t.rows) || col < 0 || col >= len(t.rows[row]) {
+		return Cell{Rune: ' '}
+	}
+	return t.rows[row][col]
+}
+
+// Write interprets p and updates the screen. It never fails.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(p)
+	if len(t.pending) > 0 {
+		p = append(t.pending, p...)
+		t.pending = nil
+	}
+
+	for len(p) > 0 {
+		c, size := utf8.DecodeRune(p)
+		if c == utf8.RuneError && !utf8.FullRune(p) {
+			t.pending = append([]byte(nil), p...)
+			break
+		}
+		p = p[size:]
+		t.put(c)
+	}
+	return n, nil
+}
+
+// Lines returns the screen rows with trailing spaces removed.
+func (t *Terminal) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, len(t.rows))
+	for i, row := range t.rows {
+		var b strings.Builder
+		for _, cell := range row {
+			b.WriteRune(cell.Rune)
+		}
+		lines[i] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// StyledLines returns the screen rows like Lines, with every run of colored
+// cells wrapped in markup such as "<32>done</>" to assert color placement.
+func (t *Terminal) StyledLines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, len(t.rows))
+	for i, row := range t.rows {
+		// Trailing blank cells without style are not visible
+		end := len(row)
+		for end > 0 && row[end-1].Rune == ' ' && row[end-1].Style == "" {
+			end--
+		}
+
+		var b strings.Builder
+		style := ""
+		for _, cell := range row[:end] {
+			if cell.Style != style {
+				if style != "" {
+					b.WriteString("</>")
+				}
+				if cell.Style != "" {
+					b.WriteString("<" + cell.Style + ">")
+				}
+				style = cell.Style
+			}
+			b.WriteRune(cell.Rune)
+		}
+		if style != "" {
+			b.WriteString("</>")
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// String returns the visible screen as lines joined with newlines,
+// without trailing empty lines.
+func (t *Terminal) String() string {
+	return joinLines(t.Lines())
+}
+
+// StyledString returns StyledLines joined like String.
+func (t *Terminal) StyledString() string {
+	return joinLines(t.StyledLines())
+}
+
+// joinLines joins lines with newlines, dropping trailing empty lines.
+func joinLines(lines []string) string {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// put feeds a single rune through the escape sequence parser.
+func (t *Terminal) put(c rune) {
+	switch t.state {
+	case stateEscape:
+		t.state = stateGround
+		switch c {
+		case '[':
+			t.state = stateCSI
+			t.params = t.params[:0]
+		case '7': // save cursor
+			t.saveRow, t.saveCol = t.row, t.col
+		case '8': // restore cursor
+			t.moveTo(t.saveRow, t.saveCol)
+		}
+		// Other escape sequences are a single character and not supported
+		return
+	case stateCSI:
+		if c >= 0x40 && c <= 0x7e {
+			t.state = stateGround
+			t.csi(c, string(t.params))
+			return
+		}
+		t.params = append(t.params, byte(c))
+		return
+	}
+
+	switch c {
+	case '\033':
+		t.state = stateEscape
+	case '\r':
+		t.col = 0
+	case '\n':
+		// Like a tty with output post-processing, newline also returns the carriage
+		t.col = 0
+		t.lineFeed()
+	case '\b':
+		t.col = max(min(t.col, t.width-1)-1, 0)
+	case '\t':
+		t.col = min((t.col/8+1)*8, t.width-1)
+	default:
+		if c < ' ' {
+			return // other control characters have no visible effect
+		}
+		t.print(c)
+	}
+}
+
+// print writes c at the cursor. Like VT100 the cursor stays on the last
+// column and wraps only when the next character is printed.
+func (t *Terminal) print(c rune) {
+	if t.col >= t.width {
+		t.col = 0
+		t.lineFeed()
+	}
+
+	row := t.rows[t.row]
+	for len(row) <= t.col {
+		row = append(row, Cell{Rune: ' '})
+	}
+	row[t.col] = Cell{Rune: c, Style: t.style}
+	t.rows[t.row] = row
+	t.col++
+}
+
+// lineFeed moves the cursor down, scrolling or growing the screen at the bottom.
+func (t *Terminal) lineFeed() {
+	t.row++
+	if t.height > 0 && t.row >= t.height {
+		t.rows = append(t.rows[1:], nil)
+		t.row = t.height - 1
+		return
+	}
+	for len(t.rows) <= t.row {
+		t.rows = append(t.rows, nil)
+	}
+}
+
+// moveTo places the cursor on the screen, growing it when it has no fixed height.
+func (t *Terminal) moveTo(row, col int) {
+	row = max(row, 0)
+	if t.height > 0 {
+		row = min(row, t.height-1)
+	}
+	for len(t.rows) <= row {
+		t.rows = append(t.rows, nil)
+	}
+	t.row = row
+	t.col = max(min(col, t.width-1), 0)
+}
+
+// csi runs a control sequence with its raw parameters.
+func (t *Terminal) csi(final rune, params string) {
+	if strings.HasPrefix(params, "?") {
+		return // private modes such as cursor visibility
+	}
+
+	args := parseParams(params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	col := min(t.col, t.width-1)
+	switch final {
+	case 'A': // cursor up
+		t.moveTo(t.row-arg(0, 1), col)
+	case 'B': // cursor down
+		t.moveTo(t.row+arg(0, 1), col)
+	case 'C': // cursor forward
+		t.moveTo(t.row, col+arg(0, 1))
+	case 'D': // cursor back
+		t.moveTo(t.row, col-arg(0, 1))
+	case 'E': // cursor next line
+		t.moveTo(t.row+arg(0, 1), 0)
+	case 'F': // cursor previous line
+		t.moveTo(t.row-arg(0, 1), 0)
+	case 'G': // cursor column
+		t.moveTo(t.row, arg(0, 1)-1)
+	case 'H', 'f': // cursor position
+		t.moveTo(arg(0, 1)-1, arg(1, 1)-1)
+	case 'K': // erase in line
+		t.eraseLine(t.row, arg(0, 0))
+	case 'J': // erase in display
+		mode := arg(0, 0)
+		t.eraseLine(t.row, mode)
+		for i := range t.rows {
+			if (mode == 0 && i > t.row) || (mode == 1 && i < t.row) || mode == 2 {
+				t.rows[i] = nil
+			}
+		}
+	case 's': // save cursor
+		t.saveRow, t.saveCol = t.row, t.col
+	case 'u': // restore cursor
+		t.moveTo(t.saveRow, t.saveCol)
+	case 'm': // select graphic rendition
+		t.sgr(args)
+	}
+}
+
+// sgr updates the style of printed cells. A reset clears all parameters,
+// anything else is appended to the active style.
+func (t *Terminal) sgr(args []int) {
+	if len(args) == 0 {
+		t.style = ""
+		return
+	}
+
+	for _, a := range args {
+		if a == 0 {
+			t.style = ""
+			continue
+		}
+		if t.style != "" {
+			t.style += ";"
+		}
+		t.style += strconv.Itoa(a)
+	}
+}
+
+// eraseLine clears the cursor's row from the cursor (0), up to the cursor (1) or entirely (2).
+func (t *Terminal) eraseLine(row, mode int) {
+	line := t.rows[row]
+	col := min(t.col, t.width-1)
+	switch mode {
+	case 0:
+		if col < len(line) {
+			t.rows[row] = line[:col]
+		}
+	case 1:
+		for i := 0; i <= col && i < len(line); i++ {
+			line[i] = Cell{Rune: ' '}
+		}
+	case 2:
+		t.rows[row] = nil
+	}
+}
+
+// parseParams splits "1;2" into numbers, treating empty parameters as 0.
+func parseParams(params string) []int {
+	if params == "" {
+		return nil
+	}
+
+	var args []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	return args
+}
@@ -0,0 +1,171 @@
+package ravantest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain text", "hello", "hello"},
+		{"carriage return overwrites", "hello\rJ", "Jello"},
+		{"newline", "one\ntwo", "one\ntwo"},
+		{"backspace", "abc\b\bX", "aXc"},
+		{"colors are ignored", "\033[32mgreen\033[0m", "green"},
+		{"erase to end of line", "hello\r\033[2C\033[K", "he"},
+		{"erase start of line", "hello\033[3D\033[1K", "   lo"},
+		{"erase whole line", "hello\033[2K", ""},
+		{"cursor up redraws previous line", "one\ntwo\033[1A\rONE", "ONE\ntwo"},
+		{"cursor position", "\033[2;3Hx", "\n  x"},
+		{"erase display", "one\ntwo\033[2J", ""},
+		{"wraps at right margin", "0123456789abc", "0123456789\nabc"},
+		{"hidden cursor is ignored", "\033[?25lbar\033[?25h", "bar"},
+		{"multibyte runes", "۴۵٪", "۴۵٪"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewTerminal(10)
+			fmt.Fprint(term, tt.input)
+			if got := term.String(); got != tt.want {
+				t.Errorf("screen = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTerminalSplitWrites verifies sequences and runes split across writes are handled.
+func TestTerminalSplitWrites(t *testing.T) {
+	term := NewTerminal(20)
+	input := "\033[31m۵۰ done\033[0m\r\033[K50 done"
+	for i := 0; i < len(input); i++ {
+		term.Write([]byte{input[i]})
+	}
+
+	if got := term.String(); got != "50 done" {
+		t.Errorf("screen = %q; want %q", got, "50 done")
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	if got := NewTerminal(42).Width(); got != 42 {
+		t.Errorf("Width() = %d; want 42", got)
+	}
+	if got := NewTerminal(0).Width(); got != 1 {
+		t.Errorf("Width() of zero terminal = %d; want 1", got)
+	}
+	if lines := NewTerminal(5).Lines(); len(lines) != 1 || strings.TrimSpace(lines[0]) != "" {
+		t.Errorf("new terminal should have one empty line, got %q", lines)
+	}
+}
+
+// TestScreenScrolls verifies a fixed height screen scrolls and tracks the cursor.
+func TestScreenScrolls(t *testing.T) {
+	screen := NewScreen(10, 2)
+	fmt.Fprint(screen, "one\ntwo\nthree")
+
+	if got := screen.String(); got != "two\nthree" {
+		t.Errorf("screen = %q; want %q", got, "two\nthree")
+	}
+	if row, col := screen.Cursor(); row != 1 || col != 5 {
+		t.Errorf("Cursor() = %d, %d; want 1, 5", row, col)
+	}
+
+	// The cursor can not leave the screen.
+	fmt.Fprint(screen, "\033[5A\033[20C")
+	if row, col := screen.Cursor(); row != 0 || col != 9 {
+		t.Errorf("Cursor() = %d, %d; want 0, 9", row, col)
+	}
+}
+
+func TestSaveRestoreCursor(t *testing.T) {
+	screen := NewScreen(10, 3)
+	fmt.Fprint(screen, "a\0337\nb\nc\0338X")
+	if got := screen.String(); got != "aX\nb\nc" {
+		t.Errorf("screen = %q", got)
+	}
+
+	fmt.Fprint(screen, "\033[3;1H\033[s\033[1;1H\033[uZ")
+	if got := screen.String(); got != "aX\nb\nZ" {
+		t.Errorf("screen = %q", got)
+	}
+}
+
+// TestStyledLines verifies colors are recorded per cell.
+func TestStyledLines(t *testing.T) {
+	term := NewTerminal(20)
+	fmt.Fprint(term, "ok \033[1;32mdone\033[0m \033[31mx\033[m!")
+
+	want := "ok <1;32>done</> <31>x</>!"
+	if got := term.StyledString(); got != want {
+		t.Errorf("StyledString() = %q; want %q", got, want)
+	}
+	if c := term.Cell(0, 3); c.Rune != 'd' || c.Style != "1;32" {
+		t.Errorf("Cell(0, 3) = %+v", c)
+	}
+	if c := term.Cell(5, 5); c.Rune != ' ' || c.Style != "" {
+		t.Errorf("Cell outside the screen = %+v", c)
+	}
+}
+
+// TestResize verifies rows are cut to the new width and the cursor stays visible.
+func TestResize(t *testing.T) {
+	screen := NewScreen(10, 3)
+	fmt.Fprint(screen, "0123456789\nab\ncd")
+
+	screen.Resize(4, 2)
+	if got := screen.String(); got != "ab\ncd" {
+		t.Errorf("screen after shrinking = %q", got)
+	}
+	if row, col := screen.Cursor(); row != 1 || col != 2 {
+		t.Errorf("Cursor() = %d, %d; want 1, 2", row, col)
+	}
+	if screen.Width() != 4 {
+		t.Errorf("Width() = %d; want 4", screen.Width())
+	}
+
+	screen.Resize(8, 4)
+	if lines := screen.Lines(); len(lines) != 4 {
+		t.Errorf("expected 4 lines after growing, got %q", lines)
+	}
+}
+
+// TestResizeSavedCursor verifies a saved cursor moves with its row and
+// stays on a smaller screen.
+func TestResizeSavedCursor(t *testing.T) {
+	screen := NewScreen(10, 5)
+	fmt.Fprint(screen, "1\n2\n3\n4\n5\033[s")
+	screen.Resize(10, 2)
+	fmt.Fprint(screen, "\033[ux")
+	if got := screen.String(); got != "4\n5x" {
+		t.Errorf("screen = %q; want x after 5", got)
+	}
+
+	screen = NewScreen(10, 5)
+	fmt.Fprint(screen, "\0337top\n2\n3")
+	screen.Resize(2, 2) // the saved row and column are cut off
+	fmt.Fprint(screen, "\0338y")
+	if got := screen.String(); got != "y" {
+		t.Errorf("screen = %q; want y over 3 at the top left", got)
+	}
+}
+
+// TestDeferredWrap verifies the cursor waits on the last column before wrapping.
+func TestDeferredWrap(t *testing.T) {
+	term := NewTerminal(4)
+	fmt.Fprint(term, "abcd")
+	if row, col := term.Cursor(); row != 0 || col != 3 {
+		t.Errorf("Cursor() = %d, %d; want 0, 3", row, col)
+	}
+
+	// A carriage return after a full line redraws the same line.
+	fmt.Fprint(term, "\rwxyz")
+	if got := term.String(); got != "wxyz" {
+		t.Errorf("screen = %q; want %q", got, "wxyz")
+	}
+}
@@ -0,0 +1,249 @@
+package ravan
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry keeps bars by name so their progress can be looked up from
+// elsewhere, e.g. by status endpoints of a server. It is safe for
+// concurrent use; the zero value is an empty registry.
+type Registry struct {
+	mu   sync.Mutex
+	bars map[string]*registryEntry
+
+	// watchMu is never held while taking the lock of a bar, the bars
+	// notify the watches with their own lock held
+	watchMu sync.Mutex
+	watches []*registryWatch
+}
+
+// registryEntry is a registered bar and the function that stops watching it.
+type registryEntry struct {
+	r       *Ravan
+	unwatch func()
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add registers the bar under name, replacing a bar of the same name.
+func (g *Registry) Add(name string, r *Ravan) {
+	e := &registryEntry{r: r}
+	e.unwatch = r.addWatcher(func(Snapshot) { g.changed(name) })
+
+	g.mu.Lock()
+	if g.bars == nil {
+		g.bars = make(map[string]*registryEntry)
+	}
+	old := g.bars[name]
+	g.bars[name] = e
+	g.mu.Unlock()
+
+	if old != nil {
+		old.unwatch()
+	}
+	g.changed(name)
+}
+
+// Remove removes the bar registered under name.
+func (g *Registry) Remove(name string) {
+	g.remove(name, nil)
+}
+
+// removeBar removes name only if it is still registered for r.
+func (g *Registry) removeBar(name string, r *Ravan) {
+	g.remove(name, r)
+}
+
+// remove removes name if it is registered for r, or for any bar if r is nil.
+func (g *Registry) remove(name string, r *Ravan) {
+	g.mu.Lock()
+	e, ok := g.bars[name]
+	if ok && (r == nil || e.r == r) {
+		delete(g.bars, name)
+	} else {
+		e = nil
+	}
+	g.mu.Unlock()
+
+	if e != nil {
+		e.unwatch()
+		g.changed(name)
+	}
+}
+
+// Get returns the bar registered under name.
+func (g *Registry) Get(name string) (*Ravan, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if e, ok := g.bars[name]; ok {
+		return e.r, true
+	}
+	return nil, false
+}
+
+// Names returns the names of all bars in sorted order.
+func (g *Registry) Names() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, 0, len(g.bars))
+	for name := range g.bars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Snapshot returns the current state of the bar registered under name.
+func (g *Registry) Snapshot(name string) (Snapshot, bool) {
+	r, ok := g.Get(name)
+	if !ok {
+		return Snapshot{}, false
+	}
+	return r.Snapshot(), true
+}
+
+// Snapshots returns the current state of all bars by name.
+func (g *Registry) Snapshots() map[string]Snapshot {
+	g.mu.Lock()
+	bars := make(map[string]*Ravan, len(g.bars))
+	for name, e := range g.bars {
+		bars[name] = e.r
+	}
+	g.mu.Unlock()
+
+	// Bars are asked without holding the registry lock
+	snaps := make(map[string]Snapshot, len(bars))
+	for name, r := range bars {
+		snaps[name] = r.Snapshot()
+	}
+	return snaps
+}
+
+// registryWatch collects the names of bars that changed since the last take.
+type registryWatch struct {
+	mu      sync.Mutex
+	names   map[string]bool
+	changed chan struct{} // receives when names is not empty
+}
+
+// watch starts collecting the names of bars that are drawn, added or removed.
+func (g *Registry) watch() (w *registryWatch, stop func()) {
+	w = &registryWatch{names: make(map[string]bool), changed: make(chan struct{}, 1)}
+
+	g.watchMu.Lock()
+	g.watches = append(g.watches, w)
+	g.watchMu.Unlock()
+
+	return w, func() {
+		g.watchMu.Lock()
+		defer g.watchMu.Unlock()
+		for i, other := range g.watches {
+			if other == w {
+				g.watches = append(g.watches[:i:i], g.watches[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// changed marks name as changed for all watches without blocking.
+func (g *Registry) changed(name string) {
+	g.watchMu.Lock()
+	defer g.watchMu.Unlock()
+
+	for _, w := range g.watches {
+		w.mu.Lock()
+		w.names[name] = true
+		w.mu.Unlock()
+		select {
+		case w.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take returns the names that changed in sorted order and starts over.
+func (w *registryWatch) take() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	names := make([]string, 0, len(w.names))
+	for name := range w.names {
+		names = append(names, name)
+	}
+	clear(w.names)
+	sort.Strings(names)
+	return names
+}
+
+// jsonStatus is the state of a named bar in responses of status endpoints.
+// Durations are written in seconds like the events of JSON output.
+type jsonStatus struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Label    string  `json:"label,omitempty"`
+	Current  int64   `json:"current"`
+	Total    int64   `json:"total"`
+	Fraction float64 `json:"fraction"`
+	Rate     float64 `json:"rate"`
+	ETA      float64 `json:"eta"`
+	Elapsed  float64 `json:"elapsed"`
+	Note     string  `json:"note,omitempty"`
+	Warnings int     `json:"warnings"`
+	Error    string  `json:"error,omitempty"`
+}
+
+func newJSONStatus(name string, s Snapshot) jsonStatus {
+	return jsonStatus{
+		Name:     name,
+		Status:   s.Status.String(),
+		Label:    s.Label,
+		Current:  s.Current,
+		Total:    s.Total,
+		Fraction: s.Fraction,
+		Rate:     s.Rate,
+		ETA:      s.ETA.Seconds(),
+		Elapsed:  s.Elapsed.Seconds(),
+		Note:     s.Note,
+		Warnings: s.Warnings,
+		Error:    errorString(s.Err),
+	}
+}
+
+// ServeHTTP answers status requests with JSON: the bar named by the
+// "name" query parameter, or a list of all bars sorted by name.
+func (g *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body interface{}
+	if name := req.URL.Query().Get("name"); name != "" {
+		s, ok := g.Snapshot(name)
+		if !ok {
+			http.Error(w, "unknown bar: "+name, http.StatusNotFound)
+			return
+		}
+		body = newJSONStatus(name, s)
+	} else {
+		snaps := g.Snapshots()
+		list := make([]jsonStatus, 0, len(snaps))
+		for name, s := range snaps {
+			list = append(list, newJSONStatus(name, s))
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		body = list
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,75 @@
+package ravan
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var reg Registry
+	a, _ := New(WithTotal(10), WithWriter(io.Discard))
+	b, _ := New(WithTotal(4), WithWriter(io.Discard))
+	reg.Add("b", b)
+	reg.Add("a", a)
+	a.Add(5)
+
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Names() = %v; want [a b]", got)
+	}
+	if s, ok := reg.Snapshot("a"); !ok || s.Current != 5 {
+		t.Errorf("Snapshot(a) = %+v, %v; want current 5", s, ok)
+	}
+	if snaps := reg.Snapshots(); len(snaps) != 2 || snaps["b"].Total != 4 {
+		t.Errorf("Snapshots() = %+v", snaps)
+	}
+
+	reg.removeBar("a", b) // another bar, a stays
+	reg.Remove("b")
+	if _, ok := reg.Get("b"); ok {
+		t.Error("b must be removed")
+	}
+	if _, ok := reg.Get("a"); !ok {
+		t.Error("a must stay registered")
+	}
+}
+
+// TestRegistryServeHTTP verifies the status endpoint answers with JSON.
+func TestRegistryServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	bar, _ := New(WithTotal(4), WithLabel("import"), WithWriter(io.Discard))
+	reg.Add("import", bar)
+	bar.Add(1)
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status?name=import", nil))
+	var status jsonStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if status.Name != "import" || status.Status != "running" || status.Current != 1 || status.Fraction != 0.25 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var list []jsonStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 {
+		t.Errorf("expected a list of one bar, got %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status?name=other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown bar: status %d; want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status %d; want 405", rec.Code)
+	}
+}
@@ -0,0 +1,329 @@
+package ravan
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// OutputMode selects how a Ravan bar is rendered.
+type OutputMode string
+
+const (
+	TerminalOutput   OutputMode = "terminal"   // redrawn bar with ANSI colors (default)
+	JSONOutput       OutputMode = "json"       // newline-delimited JSON events
+	AccessibleOutput OutputMode = "accessible" // plain announcements for screen readers
+)
+
+// isValidOutput reports whether mode is a known output mode.
+func isValidOutput(mode OutputMode) bool {
+	switch mode {
+	case TerminalOutput, JSONOutput, AccessibleOutput:
+		return true
+	default:
+		return false
+	}
+}
+
+// MessageKind tells a Renderer which kind of message is shown.
+type MessageKind int
+
+const (
+	FailedMessage MessageKind = iota
+	SuccessMessage
+	WarnMessage
+	InfoMessage
+	LogMessage // a plain line printed above the bar, see Log
+)
+
+// String returns the name used for the kind in JSON events.
+func (k MessageKind) String() string {
+	switch k {
+	case FailedMessage:
+		return "failed"
+	case SuccessMessage:
+		return "success"
+	case WarnMessage:
+		return "warn"
+	case InfoMessage:
+		return "info"
+	case LogMessage:
+		return "log"
+	default:
+		return fmt.Sprintf("MessageKind(%d)", int(k))
+	}
+}
+
+// Snapshot is an immutable copy of the state of a bar at one point in time.
+type Snapshot struct {
+	Current  int64   // items done
+	Total    int64   // total set with WithTotal, 0 if unknown
+	Fraction float64 // progress between 0.0 and 1.0
+	Rate     float64 // items per second
+	ETA      time.Duration
+	Elapsed  time.Duration
+	Status   Status
+	Label    string    // set with WithLabel or SetLabel
+	Note     string    // transient note such as the retry countdown, in English
+	Retry    RetryInfo // the retry behind Note, zero when not retrying
+	Warnings int
+	Retries  int
+	Requeued int64
+	Err      error // error passed to Finish
+}
+
+// Renderer turns bar updates into output. Ravan keeps the bookkeeping
+// and hands every change to the renderer as a Snapshot.
+// All methods are called with the bar's lock held, so a Renderer
+// must not call methods of the bar it renders.
+type Renderer interface {
+	// Start is called once before the first update of the bar.
+	Start(s Snapshot)
+	// Progress is called whenever the progress or its note changes.
+	Progress(s Snapshot)
+	// Message is called for FailMsg, SuccessMsg, WarnMsg, InfoMsg, Log and Finish.
+	// text is the custom or default message and err is the optional error.
+	Message(s Snapshot, kind MessageKind, err error, text string)
+	// Finish is called once when the bar is finished.
+	Finish(s Snapshot)
+}
+
+// widthTTL is how long the width of a terminal file is cached,
+// so hot loops don't pay for an ioctl on every redraw.
+const widthTTL = 500 * time.Millisecond
+
+// terminalRenderer redraws the bar on a single line using carriage returns.
+type terminalRenderer struct {
+	r        *Ravan
+	lineOpen bool   // a partial bar line is on screen without a trailing newline
+	lineLen  int    // visible length of the partial bar line
+	lastLine string // partial bar line on screen
+	width    int    // cached terminal width
+	widthAt  time.Time
+}
+
+func (t *terminalRenderer) Start(s Snapshot) {}
+
+func (t *terminalRenderer) Progress(s Snapshot) {
+	termWidth := t.termWidth()
+	line := t.r.renderLine(s, termWidth)
+
+	if s.Fraction >= 1.0 {
+		fmt.Fprintf(t.r.stdout(), "\r%s\n", line)
+		t.lineOpen = false
+		t.lineLen = 0
+		return
+	}
+
+	if t.lineOpen && line == t.lastLine {
+		return // nothing visible changed
+	}
+
+	// Pad with spaces to wipe leftovers of a longer previous line,
+	// but never past the right margin of a terminal that was made narrower
+	lineLen := utf8.RuneCountInString(line)
+	clearTo := t.lineLen
+	if termWidth > 0 && clearTo > termWidth {
+		clearTo = termWidth
+	}
+	padding := ""
+	if t.lineOpen && clearTo > lineLen {
+		padding = strings.Repeat(" ", clearTo-lineLen)
+	}
+	fmt.Fprintf(t.r.stdout(), "\r%s%s", line, padding)
+	t.lineOpen = true
+	t.lineLen = lineLen
+	t.lastLine = line
+}
+
+// termWidth returns the width of the bar's writer. The width of a terminal
+// file is cached for widthTTL, other writers such as ravantest.Terminal
+// are asked every time.
+func (t *terminalRenderer) termWidth() int {
+	w := t.r.stdout()
+	if _, ok := w.(*os.File); !ok {
+		return widthOf(w)
+	}
+
+	now := time.Now()
+	if t.widthAt.IsZero() || now.Sub(t.widthAt) > widthTTL {
+		t.width = widthOf(w)
+		t.widthAt = now
+	}
+	return t.width
+}
+
+func (t *terminalRenderer) Message(s Snapshot, kind MessageKind, err error, text string) {
+	if kind == LogMessage {
+		t.log(text)
+		return
+	}
+
+	msg := t.r.formatMessage(s, kind, err, text)
+
+	// Failures always start on a new line, other messages only
+	// move below an unfinished bar so the next Draw continues on a fresh line.
+	prefix := ""
+	if kind == FailedMessage || t.lineOpen {
+		prefix = "\n"
+	}
+	fmt.Fprint(t.r.stdout(), prefix+msg+"\n")
+	t.lineOpen = false
+	t.lineLen = 0
+}
+
+func (t *terminalRenderer) Finish(s Snapshot) {}
+
+// log replaces an unfinished bar with text and draws the bar again below it.
+func (t *terminalRenderer) log(text string) {
+	clearTo := t.lineLen
+	if w := t.termWidth(); w > 0 && clearTo > w {
+		clearTo = w
+	}
+	padding := ""
+	if n := utf8.RuneCountInString(text); t.lineOpen && clearTo > n {
+		padding = strings.Repeat(" ", clearTo-n)
+	}
+	fmt.Fprint(t.r.stdout(), "\r"+text+padding+"\n")
+	if t.lineOpen {
+		fmt.Fprint(t.r.stdout(), t.lastLine)
+	}
+}
+
+// renderLine returns the bar line for s in termWidth columns, without
+// carriage return or newline. A termWidth of 0 falls back to the bar width.
+// The line is printed in the theme's success color when complete.
+func (r *Ravan) renderLine(s Snapshot, termWidth int) string {
+	if termWidth == 0 {
+		termWidth = r.width // fallback if terminal width cannot be determined
+	}
+
+	l := r.locale
+	note := l.localizeDigits(s.Note)
+	if s.Retry.Attempt > 0 {
+		note = l.retryNote(s.Retry)
+	}
+	stats := r.statsText(s)
+
+	label := ""
+	if s.Label != "" {
+		label = s.Label + " "
+	}
+
+	// Without a total there is no bar to draw, only the stats
+	if stats != "" && s.Total <= 0 && s.Fraction == 0 {
+		return strings.TrimSpace(label + stats + " " + note)
+	}
+
+	// Overhead accounts for extra characters like "[", "]", " 100%"
+	overhead := 7
+	if s.Label != "" {
+		overhead += utf8.RuneCountInString(s.Label) + 1
+	}
+	if note != "" {
+		overhead += utf8.RuneCountInString(note) + 1
+	}
+	if stats != "" {
+		overhead += utf8.RuneCountInString(stats) + 1
+	}
+	effectiveWidth := r.width
+	if termWidth-overhead < effectiveWidth {
+		effectiveWidth = termWidth - overhead
+		if effectiveWidth < 1 {
+			effectiveWidth = 1
+		}
+	}
+
+	progress := s.Fraction
+	complete := int(progress * float64(effectiveWidth))
+	bar := strings.Repeat(string(r.completeChar), complete) +
+		strings.Repeat(string(r.incompleteChar), effectiveWidth-complete)
+	if l.RTL {
+		// Fill from the right edge towards the left
+		bar = strings.Repeat(string(r.incompleteChar), effectiveWidth-complete) +
+			strings.Repeat(string(r.completeChar), complete)
+	}
+
+	line := fmt.Sprintf("%s[%s] %s", label, bar, l.percent(progress))
+	if stats != "" {
+		line += " " + stats
+	}
+	if progress >= 1.0 {
+		// Print in green when complete
+		return paint(r.colors().Success, line)
+	}
+
+	if note != "" {
+		line += " " + note
+	}
+	return line
+}
+
+// formatMessage returns a message in the color of its kind, see message.
+func (r *Ravan) formatMessage(s Snapshot, kind MessageKind, err error, text string) string {
+	colors := r.colors()
+	var color string
+	switch kind {
+	case FailedMessage:
+		color = colors.Failed
+	case SuccessMessage:
+		color = colors.Success
+	case WarnMessage:
+		color = colors.Warn
+	case InfoMessage:
+		color = colors.Info
+	}
+	return paint(color, r.locale.message(s, kind, err, text))
+}
+
+// message returns the text of a message with its localized prefix, e.g.
+// "Error: disk full. Operation failed (2 warnings)".
+func (l Locale) message(s Snapshot, kind MessageKind, err error, text string) string {
+	c := l.catalog()
+	msg := strings.Builder{}
+
+	switch kind {
+	case FailedMessage:
+		if err != nil {
+			msg.WriteString(fmt.Sprintf("%s%s. ", c.ErrorPrefix, l.isolateError(err)))
+		}
+		msg.WriteString(l.isolate(text))
+		msg.WriteString(l.summary(s))
+		return msg.String()
+	case SuccessMessage:
+		return c.SuccessPrefix + l.isolate(text) + l.summary(s)
+	case WarnMessage:
+		msg.WriteString(c.WarnPrefix)
+	case InfoMessage:
+		msg.WriteString(c.InfoPrefix)
+	case LogMessage:
+		return text
+	}
+	if err != nil {
+		msg.WriteString(fmt.Sprintf("%s. ", l.isolateError(err)))
+	}
+	msg.WriteString(l.isolate(text))
+	return msg.String()
+}
+
+// summary returns the warning and retry statistics suffix for final messages.
+func (l Locale) summary(s Snapshot) string {
+	c := l.catalog()
+	var parts []string
+	if s.Warnings > 0 {
+		parts = append(parts, l.plural(int64(s.Warnings), c.Warning))
+	}
+	if s.Retries > 0 {
+		parts = append(parts, l.plural(int64(s.Retries), c.Retry))
+	}
+	if s.Requeued > 0 {
+		parts = append(parts, fmt.Sprintf(c.Requeued, l.FormatInt(s.Requeued)))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, c.ListSep) + ")"
+}
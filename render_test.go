@@ -0,0 +1,158 @@
+package ravan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// recordRenderer records every call as a short line for assertions.
+type recordRenderer struct {
+	calls []string
+	last  Snapshot
+}
+
+func (rr *recordRenderer) Start(s Snapshot) {
+	rr.calls = append(rr.calls, "start")
+	rr.last = s
+}
+
+func (rr *recordRenderer) Progress(s Snapshot) {
+	rr.calls = append(rr.calls, fmt.Sprintf("progress %d/%d %s", s.Current, s.Total, s.Label))
+	rr.last = s
+}
+
+func (rr *recordRenderer) Message(s Snapshot, kind MessageKind, err error, text string) {
+	rr.calls = append(rr.calls, fmt.Sprintf("message %s %s", kind, text))
+	rr.last = s
+}
+
+func (rr *recordRenderer) Finish(s Snapshot) {
+	rr.calls = append(rr.calls, "finish "+s.Status.String())
+	rr.last = s
+}
+
+// TestWithRenderer verifies a custom renderer receives every update instead of stdout.
+func TestWithRenderer(t *testing.T) {
+	rr := &recordRenderer{}
+	r, err := New(WithRenderer(rr), WithTotal(3), WithLabel("copy"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	output := captureOutput(func() {
+		r.Increment()
+		r.SetLabel("verify")
+		r.InfoMsg("checking")
+		r.Finish(errors.New("boom"))
+	})
+	if output != "" {
+		t.Errorf("expected no stdout output with a custom renderer, got %q", output)
+	}
+
+	want := []string{
+		"start",
+		"progress 1/3 copy",
+		"progress 1/3 verify",
+		"message info checking",
+		"message failed Operation failed",
+		"finish failed",
+	}
+	if got := strings.Join(rr.calls, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("calls =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+	if rr.last.Err == nil || rr.last.Err.Error() != "boom" {
+		t.Errorf("expected error in final snapshot, got %v", rr.last.Err)
+	}
+}
+
+// TestSnapshot verifies the bookkeeping exposed by Snapshot.
+func TestSnapshot(t *testing.T) {
+	r, _ := New(WithWriter(&bytes.Buffer{}), WithTotal(10), WithLabel("import"))
+
+	s := r.Snapshot()
+	if s.Current != 0 || s.Total != 10 || s.Status != Running || s.Label != "import" {
+		t.Errorf("unexpected initial snapshot %+v", s)
+	}
+
+	r.Add(4)
+	s = r.Snapshot()
+	if s.Current != 4 || s.Fraction != 0.4 {
+		t.Errorf("unexpected snapshot after Add %+v", s)
+	}
+	if s.Elapsed <= 0 || s.Rate <= 0 || s.ETA <= 0 {
+		t.Errorf("expected elapsed, rate and ETA to be set, got %+v", s)
+	}
+
+	// A snapshot is a copy and does not change with the bar.
+	r.Add(6)
+	if s.Current != 4 {
+		t.Errorf("snapshot changed after update: %+v", s)
+	}
+
+	r.Finish(nil)
+	s = r.Snapshot()
+	if s.Status != Succeeded || s.ETA != 0 {
+		t.Errorf("unexpected final snapshot %+v", s)
+	}
+}
+
+// TestLabel verifies the label is drawn in front of the bar.
+func TestLabel(t *testing.T) {
+	var buf bytes.Buffer
+	r, _ := New(WithWriter(&buf), WithWidth(20), WithLabel("copy"), WithTotal(2))
+
+	r.Increment()
+	if got := buf.String(); !strings.HasPrefix(got, "\rcopy [") || !strings.HasSuffix(got, "] 50%") {
+		t.Errorf("unexpected labeled bar %q", got)
+	}
+}
+
+func TestWithRendererNil(t *testing.T) {
+	if _, err := New(WithRenderer(nil)); err == nil {
+		t.Error("expected error for nil renderer")
+	}
+}
+
+// TestTerminalRendererScreen verifies what the user sees after redraws, a
+// notice, a terminal resize and the final colored line.
+func TestTerminalRendererScreen(t *testing.T) {
+	screen := ravantest.NewScreen(40, 6)
+	r, _ := New(WithWriter(screen), WithWidth(30), WithTotal(10))
+
+	r.Add(3)
+	r.Retry(2, 3, 0)
+	r.Add(2)
+	if got := screen.String(); got != "[===============               ] 50%" {
+		t.Errorf("redraw left stale output: %q", got)
+	}
+
+	r.InfoMsg("halfway")
+	screen.Resize(20, 6)
+	r.Add(1)
+	r.Finish(nil)
+
+	want := strings.Join([]string{
+		"[===============",
+		"Info: halfway",
+		"[=======      ] 60%",
+		"Success: Operation s",
+		"uccessful (1 retry)",
+	}, "\n")
+	if got := screen.String(); got != want {
+		t.Errorf("screen =\n%s\nwant\n%s", got, want)
+	}
+
+	// Only the messages are colored, the unfinished bar is not.
+	styled := screen.StyledLines()
+	if styled[1] != "<36>Info: halfway</>" || styled[2] != "[=======      ] 60%" || styled[4] != "<32>uccessful (1 retry)</>" {
+		t.Errorf("unexpected colors %q", styled)
+	}
+	if row, col := screen.Cursor(); row != 5 || col != 0 {
+		t.Errorf("Cursor() = %d, %d; want 5, 0", row, col)
+	}
+}
@@ -0,0 +1,123 @@
+package ravan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status describes the state of a Ravan progress bar.
+type Status int
+
+const (
+	Running   Status = iota // bar is still accepting updates
+	Succeeded               // finished without error
+	Failed                  // finished with an error
+	Aborted                 // finished because the work was canceled
+)
+
+// ErrAborted can be passed to Finish to mark a bar as aborted.
+// context.Canceled is treated the same way.
+var ErrAborted = errors.New("operation aborted")
+
+// String returns the lower case name of the status.
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	case Aborted:
+		return "aborted"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Result holds the outcome of a finished progress bar.
+type Result struct {
+	Status   Status
+	Current  int64   // final count of items
+	Total    int64   // total set with WithTotal, 0 if unknown
+	Progress float64 // final progress between 0.0 and 1.0
+	Duration time.Duration
+	Warnings int   // number of WarnMsg calls during the run
+	Retries  int   // number of Retry calls during the run
+	Requeued int64 // items rolled back with Requeue
+	Err      error
+}
+
+// Finish records the terminal state of the bar and prints the matching message.
+// A nil err means the operation succeeded, ErrAborted or context.Canceled
+// mean it was aborted and any other error means it failed.
+// After Finish, Draw and Add no longer render anything.
+// Calling Finish more than once returns the first Result without printing.
+func (r *Ravan) Finish(err error) Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != Running {
+		return r.result
+	}
+
+	status := Succeeded
+	switch {
+	case errors.Is(err, ErrAborted), errors.Is(err, context.Canceled):
+		status = Aborted
+	case err != nil:
+		status = Failed
+	}
+
+	var duration time.Duration
+	if !r.start.IsZero() {
+		duration = r.now().Sub(r.start)
+	}
+
+	if r.ticker != nil {
+		// Show the last count of the Counter before the message
+		r.stopTicker()
+		r.updateProgress()
+		r.flush()
+	}
+	r.flushPending()
+	if r.total > 0 && r.frame.drawn != r.current.Load() {
+		// Add draws every 0.1% only, show the count the bar ends with
+		r.updateProgress()
+		r.flush()
+	}
+	r.stopRetry()
+	r.stopFrames()
+	r.updateProgress()
+	r.status = status
+	r.result = Result{
+		Status:   status,
+		Current:  r.current.Load(),
+		Total:    r.total,
+		Progress: r.progress,
+		Duration: duration,
+		Warnings: r.warnings,
+		Retries:  r.retries,
+		Requeued: r.requeued,
+		Err:      err,
+	}
+
+	out := r.output()
+	if status == Succeeded {
+		out.Message(r.state(), SuccessMessage, nil, r.message.Success)
+	} else {
+		out.Message(r.state(), FailedMessage, err, r.message.Failed)
+	}
+	out.Finish(r.state())
+
+	return r.result
+}
+
+// Status reports the current state of the bar.
+func (r *Ravan) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
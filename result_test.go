@@ -0,0 +1,133 @@
+package ravan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestFinishStatus verifies Finish maps errors to the right terminal state.
+func TestFinishStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Status
+	}{
+		{"nil error", nil, Succeeded},
+		{"plain error", errors.New("boom"), Failed},
+		{"aborted", ErrAborted, Aborted},
+		{"context canceled", context.Canceled, Aborted},
+		{"wrapped canceled", fmt.Errorf("copy: %w", context.Canceled), Aborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New()
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+
+			var res Result
+			captureOutput(func() {
+				res = r.Finish(tt.err)
+			})
+
+			if res.Status != tt.want {
+				t.Errorf("Finish(%v).Status = %v; want %v", tt.err, res.Status, tt.want)
+			}
+			if r.Status() != tt.want {
+				t.Errorf("Status() = %v; want %v", r.Status(), tt.want)
+			}
+			if res.Err != tt.err {
+				t.Errorf("Finish(%v).Err = %v", tt.err, res.Err)
+			}
+		})
+	}
+}
+
+// TestFinishResult verifies the Result carries the final count and progress.
+func TestFinishResult(t *testing.T) {
+	r, err := New(WithTotal(4))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	var res Result
+	captureOutput(func() {
+		r.Increment()
+		r.Add(2)
+		res = r.Finish(nil)
+	})
+
+	if res.Current != 3 || res.Total != 4 {
+		t.Errorf("got %d/%d; want 3/4", res.Current, res.Total)
+	}
+	if res.Progress != 0.75 {
+		t.Errorf("got progress %v; want 0.75", res.Progress)
+	}
+	if res.Duration <= 0 {
+		t.Errorf("expected positive duration, got %v", res.Duration)
+	}
+}
+
+// TestFinishStopsDrawing verifies Draw and Add are ignored after Finish.
+func TestFinishStopsDrawing(t *testing.T) {
+	r, err := New(WithTotal(10))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	captureOutput(func() {
+		r.Finish(errors.New("boom"))
+	})
+
+	output := captureOutput(func() {
+		r.Draw(0.5)
+		r.Add(5)
+	})
+	if output != "" {
+		t.Errorf("expected no output after Finish, got %q", output)
+	}
+
+	var res Result
+	output = captureOutput(func() {
+		res = r.Finish(nil)
+	})
+	if output != "" {
+		t.Errorf("expected second Finish to print nothing, got %q", output)
+	}
+	if res.Status != Failed || res.Current != 0 {
+		t.Errorf("second Finish should return first result, got %+v", res)
+	}
+}
+
+// TestFinishMessages verifies Finish prints the success or failure message.
+func TestFinishMessages(t *testing.T) {
+	r, _ := New()
+	output := captureOutput(func() {
+		r.Draw(0.5)
+		r.Finish(nil)
+	})
+	if !strings.HasSuffix(output, "\n"+DefaultTheme.Success+"Success: Operation successful"+resetColor+"\n") {
+		t.Errorf("unexpected success output %q", output)
+	}
+
+	r, _ = New()
+	output = captureOutput(func() {
+		r.Finish(errors.New("boom"))
+	})
+	if !strings.Contains(output, "Error: boom. Operation failed") {
+		t.Errorf("unexpected failure output %q", output)
+	}
+}
+
+func TestStatusString(t *testing.T) {
+	if got := Aborted.String(); got != "aborted" {
+		t.Errorf("Aborted.String() = %q", got)
+	}
+	if got := Status(42).String(); got != "Status(42)" {
+		t.Errorf("Status(42).String() = %q", got)
+	}
+}
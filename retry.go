@@ -0,0 +1,148 @@
+package ravan
+
+import (
+	"fmt"
+	"time"
+)
+
+// retryState holds the attempt shown next to the bar while waiting for a retry.
+type retryState struct {
+	attempt  int
+	max      int
+	deadline time.Time
+	done     chan struct{}
+}
+
+// RetryInfo describes the retry shown next to the bar.
+type RetryInfo struct {
+	Attempt int
+	Max     int           // 0 when the number of attempts is unlimited
+	Wait    time.Duration // time left until the retry, 0 when due
+}
+
+// Retry shows that the current item is retried without losing progress,
+// e.g. "retrying 2/5 in 3s". The countdown is redrawn every second until
+// wait has passed or the bar is updated again with Draw or Add.
+// A max of 0 or less means the number of attempts is unlimited.
+func (r *Ravan) Retry(attempt, max int, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != Running {
+		return
+	}
+
+	r.stopRetry()
+	r.retries++
+	st := &retryState{
+		attempt:  attempt,
+		max:      max,
+		deadline: r.now().Add(wait),
+		done:     make(chan struct{}),
+	}
+	r.retry = st
+	r.nextDraw.Store(0) // the next Add has to clear the notice
+	r.flush()
+
+	if wait > 0 {
+		go r.countdown(st)
+	}
+}
+
+// Requeue rolls the bar back by n items that have to be processed again.
+func (r *Ravan) Requeue(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != Running || n <= 0 {
+		return
+	}
+
+	if current := r.current.Load(); n > current {
+		n = current
+	}
+	r.current.Add(-n)
+	r.requeued += n
+	r.updateProgress()
+	r.scheduleNextDraw()
+	r.flush()
+}
+
+// countdown redraws the bar on every second boundary of the retry wait.
+func (r *Ravan) countdown(st *retryState) {
+	for {
+		remaining := st.deadline.Sub(r.now())
+		tick := remaining % time.Second
+		switch {
+		case remaining <= 0:
+			tick = 0 // the clock passed the deadline, draw the end right away
+		case tick == 0:
+			tick = time.Second
+		}
+		timer := time.NewTimer(tick)
+
+		select {
+		case <-st.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		r.mu.Lock()
+		if r.retry != st {
+			r.mu.Unlock()
+			return
+		}
+		r.flush()
+		expired := !r.now().Before(st.deadline)
+		r.mu.Unlock()
+
+		if expired {
+			return
+		}
+	}
+}
+
+// stopRetry clears the retry notice and stops its countdown. r.mu must be held.
+func (r *Ravan) stopRetry() {
+	if r.retry == nil {
+		return
+	}
+	close(r.retry.done)
+	r.retry = nil
+}
+
+// retryInfo returns the pending retry for snapshots. r.mu must be held.
+func (r *Ravan) retryInfo() RetryInfo {
+	st := r.retry
+	if st == nil {
+		return RetryInfo{}
+	}
+
+	info := RetryInfo{Attempt: st.attempt, Max: max(st.max, 0)}
+	if remaining := st.deadline.Sub(r.now()); remaining > 0 {
+		info.Wait = remaining
+	}
+	return info
+}
+
+// retryNote returns the retry text shown after the bar, e.g. "retrying 2/5 in 3s".
+func (l Locale) retryNote(info RetryInfo) string {
+	if info.Attempt == 0 {
+		return ""
+	}
+
+	c := l.catalog()
+	attempt := l.FormatInt(int64(info.Attempt))
+	if info.Max > 0 {
+		attempt += "/" + l.FormatInt(int64(info.Max))
+	}
+	note := fmt.Sprintf(c.Retrying, attempt)
+	if info.Wait <= 0 {
+		return note
+	}
+
+	// Round up so the countdown never shows 0s while still waiting
+	wait := (info.Wait + time.Second - 1).Truncate(time.Second)
+	return fmt.Sprintf(c.RetryIn, note, l.FormatDuration(wait))
+}
@@ -0,0 +1,108 @@
+package ravan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// TestRetryNote verifies the retry state is shown next to the bar and cleared on the next update.
+func TestRetryNote(t *testing.T) {
+	r, err := New(WithWidth(10), WithTotal(10))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	output := captureOutput(func() {
+		r.Add(4)
+		r.Retry(2, 5, 3*time.Second)
+	})
+	if !strings.HasSuffix(output, "] 40% retrying 2/5 in 3s") {
+		t.Errorf("unexpected retry output %q", output)
+	}
+
+	output = captureOutput(func() {
+		r.Increment()
+	})
+	if strings.Contains(output, "retrying") {
+		t.Errorf("expected retry note to be cleared, got %q", output)
+	}
+	// The shorter line is padded to wipe the old retry note.
+	trimmed := strings.TrimRight(output, " ")
+	if !strings.HasSuffix(trimmed, "50%") || len(output) != len("\r[ ] 40% retrying 2/5 in 3s") {
+		t.Errorf("expected padding after cleared retry note, got %q", output)
+	}
+}
+
+// TestRetryCountdown verifies the countdown redraws the bar once the wait has passed.
+func TestRetryCountdown(t *testing.T) {
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	term := ravantest.NewTerminal(80)
+	r, _ := New(WithWidth(10), WithWriter(term), WithClock(clock))
+
+	r.Retry(1, 0, 1010*time.Millisecond)
+	if got := term.String(); got != "[          ] 0% retrying 1 in 2s" {
+		t.Errorf("screen = %q; want the countdown", got)
+	}
+
+	clock.Advance(1010 * time.Millisecond)
+	if !eventually(func() bool { return term.String() == "[          ] 0% retrying 1" }) {
+		t.Errorf("screen = %q; want the countdown to finish", term.String())
+	}
+}
+
+// eventually polls cond for up to a second, for updates drawn by timers.
+func eventually(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// TestRequeue verifies progress is rolled back and counted.
+func TestRequeue(t *testing.T) {
+	r, _ := New(WithWidth(10), WithTotal(10))
+
+	var res Result
+	output := captureOutput(func() {
+		r.Add(5)
+		r.Requeue(2)
+		r.Requeue(10) // can not roll back below zero
+		r.Retry(1, 3, 0)
+		res = r.Finish(errors.New("gave up"))
+	})
+
+	if res.Current != 0 || res.Requeued != 5 || res.Retries != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if !strings.Contains(output, "] 30%") {
+		t.Errorf("expected rolled back bar, got %q", output)
+	}
+	if !strings.Contains(output, "Operation failed (1 retry, 5 requeued)") {
+		t.Errorf("expected retry statistics in final message, got %q", output)
+	}
+}
+
+// TestRequeueRedraws verifies Add draws again right after a rollback.
+func TestRequeueRedraws(t *testing.T) {
+	w := &countingWriter{}
+	r, _ := New(WithWriter(w), WithWidth(10), WithTotal(100000), WithMaxFPS(0))
+
+	r.Add(50000)
+	r.Requeue(30000)
+	w.buf.Reset()
+	for i := 0; i < 20000; i++ {
+		r.Increment()
+	}
+
+	if got := w.buf.String(); !strings.Contains(got, "] 21%") || !strings.HasSuffix(got, "] 40%") {
+		t.Errorf("expected the bar to count from 20%% to 40%%, got %q", got)
+	}
+}
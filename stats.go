@@ -0,0 +1,72 @@
+package ravan
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Units selects how WithStats writes counts and rates.
+type Units int
+
+const (
+	Items Units = iota // plain counts, e.g. "120/400 12/s"
+	Bytes              // binary sizes, e.g. "1.2 MiB/4.0 GiB 3.4 MiB/s"
+)
+
+// WithStats shows the count, the rate and the ETA after the percentage,
+// e.g. "[=====     ] 50% 2.0 GiB/4.0 GiB 35.2 MiB/s ETA 58s".
+// Without a total the bar is left out and only the count and rate are shown.
+func WithStats(u Units) Option {
+	return func(r *Ravan) error {
+		if u != Items && u != Bytes {
+			return fmt.Errorf("invalid units: %d", u)
+		}
+		r.stats = true
+		r.units = u
+		return nil
+	}
+}
+
+// statsText returns the stats shown after the percentage, or "" without WithStats.
+func (r *Ravan) statsText(s Snapshot) string {
+	if !r.stats {
+		return ""
+	}
+
+	l := r.locale
+	text := r.units.format(l, float64(s.Current))
+	if s.Total > 0 {
+		text += "/" + r.units.format(l, float64(s.Total))
+	}
+	text += " " + r.units.format(l, s.Rate) + "/s"
+	if s.ETA >= time.Second/2 && s.Fraction < 1 {
+		text += " " + fmt.Sprintf(l.catalog().ETA, l.FormatDuration(s.ETA))
+	}
+	return text
+}
+
+// format writes n in the units, with one decimal for scaled and small values.
+func (u Units) format(l Locale, n float64) string {
+	if u == Bytes {
+		return formatBytes(l, n)
+	}
+	if n < 10 && n != math.Trunc(n) {
+		return l.FormatFloat(n, 1)
+	}
+	return l.FormatInt(int64(math.Round(n)))
+}
+
+// formatBytes writes n bytes with binary prefixes, e.g. "512 B" or "1.5 KiB".
+func formatBytes(l Locale, n float64) string {
+	if n < 1024 {
+		return l.FormatInt(int64(math.Round(n))) + " B"
+	}
+
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	unit := 0
+	for n /= 1024; n >= 1024 && unit < len(units)-1; unit++ {
+		n /= 1024
+	}
+	return l.FormatFloat(n, 1) + " " + units[unit]
+}
@@ -0,0 +1,66 @@
+package ravan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		l    Locale
+		n    float64
+		want string
+	}{
+		{DefaultLocale, 0, "0 B"},
+		{DefaultLocale, 1023, "1023 B"},
+		{DefaultLocale, 1536, "1.5 KiB"},
+		{DefaultLocale, 4 << 30, "4.0 GiB"},
+		{EnglishLocale, 2000 << 50, "2,000.0 PiB"},
+		{GermanLocale, 35.2 * (1 << 20), "35,2 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.l, tt.n); got != tt.want {
+			t.Errorf("formatBytes(%v) = %q; want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestWithStats verifies the count, rate and ETA are shown after the percentage
+// and that the bar makes room for them.
+func TestWithStats(t *testing.T) {
+	r, err := New(WithWidth(10), WithStats(Bytes), WithTheme(Theme{}))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	s := Snapshot{Current: 2 << 30, Total: 4 << 30, Fraction: 0.5, Rate: 35.2 * (1 << 20), ETA: 58 * time.Second}
+	if got, want := r.renderLine(s, 80), "[=====     ] 50% 2.0 GiB/4.0 GiB 35.2 MiB/s ETA 58s"; got != want {
+		t.Errorf("renderLine() = %q; want %q", got, want)
+	}
+	if got, want := r.renderLine(s, 50), "[====    ] 50% 2.0 GiB/4.0 GiB 35.2 MiB/s ETA 58s"; got != want {
+		t.Errorf("narrow renderLine() = %q; want %q", got, want)
+	}
+
+	s = Snapshot{Current: 5, Total: 5, Fraction: 1, Rate: 2.5}
+	r, _ = New(WithWidth(10), WithStats(Items), WithTheme(Theme{}))
+	if got, want := r.renderLine(s, 80), "[==========] 100% 5/5 2.5/s"; got != want {
+		t.Errorf("renderLine() = %q; want %q", got, want)
+	}
+}
+
+// TestWithStatsUnknownTotal verifies only the stats are shown without a total.
+func TestWithStatsUnknownTotal(t *testing.T) {
+	r, _ := New(WithStats(Items), WithLabel("lines"), WithLocale(PersianLocale))
+
+	s := Snapshot{Current: 1200, Rate: 40, Label: "lines"}
+	if got, want := r.renderLine(s, 80), "lines ۱٬۲۰۰ ۴۰/s"; got != want {
+		t.Errorf("renderLine() = %q; want %q", got, want)
+	}
+}
+
+func TestWithStatsInvalidUnits(t *testing.T) {
+	if _, err := New(WithStats(Units(7))); err == nil {
+		t.Error("expected error for invalid units")
+	}
+}
@@ -0,0 +1,42 @@
+package ravan
+
+// Theme holds the ANSI color codes used for the bar and its messages.
+// An empty color prints the text without escape codes.
+type Theme struct {
+	Success string // completed bar and success message
+	Failed  string // failure message
+	Warn    string // warning message
+	Info    string // info message
+}
+
+// DefaultTheme is used when no theme is set with WithTheme.
+var DefaultTheme = Theme{
+	Success: "\033[32m", // green
+	Failed:  "\033[31m", // red
+	Warn:    "\033[33m", // yellow
+	Info:    "\033[36m", // cyan
+}
+
+// WithTheme sets the colors of the bar and its messages.
+func WithTheme(t Theme) Option {
+	return func(r *Ravan) error {
+		r.theme = &t
+		return nil
+	}
+}
+
+// colors returns the theme of the bar, falling back to DefaultTheme.
+func (r *Ravan) colors() Theme {
+	if r.theme == nil {
+		return DefaultTheme
+	}
+	return *r.theme
+}
+
+// paint wraps s in color and a reset sequence.
+func paint(color, s string) string {
+	if color == "" {
+		return s
+	}
+	return color + s + resetColor
+}
@@ -0,0 +1,46 @@
+package ravan
+
+import (
+	"testing"
+)
+
+// TestWithTheme verifies custom colors are used for the completed bar and messages.
+func TestWithTheme(t *testing.T) {
+	theme := Theme{Success: "\033[34m", Failed: "\033[35m"}
+	r, err := New(WithWidth(10), WithTheme(theme))
+	if err != nil {
+		t.Fatalf("New(WithTheme) error: %v", err)
+	}
+
+	output := captureOutput(func() {
+		r.SuccessMsg()
+	})
+	want := "\033[34mSuccess: Operation successful\033[0m\n"
+	if output != want {
+		t.Errorf("SuccessMsg() = %q; want %q", output, want)
+	}
+
+	// Warn color is empty in the custom theme, so no escape codes are printed.
+	output = captureOutput(func() {
+		r.WarnMsg("careful")
+	})
+	want = "Warning: careful\n"
+	if output != want {
+		t.Errorf("WarnMsg() = %q; want %q", output, want)
+	}
+}
+
+func TestPaint(t *testing.T) {
+	tests := []struct {
+		color, s, want string
+	}{
+		{"", "plain", "plain"},
+		{"\033[31m", "red", "\033[31mred\033[0m"},
+	}
+
+	for _, tt := range tests {
+		if got := paint(tt.color, tt.s); got != tt.want {
+			t.Errorf("paint(%q, %q) = %q; want %q", tt.color, tt.s, got, tt.want)
+		}
+	}
+}
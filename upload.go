@@ -0,0 +1,118 @@
+package ravan
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader is the header with the ID of an upload. Clients that
+// set it can ask for the progress while the body is still being sent.
+const RequestIDHeader = "X-Request-ID"
+
+// uploadRetention is how long a finished upload stays in the registry.
+const uploadRetention = time.Minute
+
+type requestIDKey struct{}
+
+// RequestID returns the ID TrackUploads gave the request of ctx.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// TrackUploads is middleware that counts the request body of every
+// request with a bar registered in reg under the request ID. The ID is
+// taken from the X-Request-ID header or generated, returned in the same
+// response header and available to next with RequestID.
+//
+// The bar uses Content-Length as total and WithStats(Bytes); it is
+// rendered nowhere unless opts set a writer or renderer. It finishes
+// when next returns: successfully if the body was read to the end,
+// aborted if next stopped reading and failed if reading the body failed.
+// Finished uploads stay in reg for a minute so clients can ask for the
+// outcome.
+func TrackUploads(reg *Registry, next http.Handler, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+
+		length := req.ContentLength // -1 if unknown
+		base := []Option{WithTotal(max(length, 0)), WithStats(Bytes), WithLabel(id), WithWriter(io.Discard)}
+		bar, err := New(append(base, opts...)...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		reg.Add(id, bar)
+
+		body := &countingBody{ReadCloser: req.Body, r: bar}
+		req.Body = body
+		req = req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+		w.Header().Set(RequestIDHeader, id)
+
+		defer func() {
+			bar.Finish(body.result(length))
+			time.AfterFunc(uploadRetention, func() { reg.removeBar(id, bar) })
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// newRequestID returns a random ID for a request without X-Request-ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// countingBody adds the bytes read from a request body to a bar.
+type countingBody struct {
+	io.ReadCloser
+	r *Ravan
+
+	mu   sync.Mutex
+	read int64
+	eof  bool
+	err  error // first read error other than io.EOF
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		c.r.Add(int64(n))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.read += int64(n)
+	if errors.Is(err, io.EOF) {
+		c.eof = true
+	} else if err != nil && c.err == nil {
+		c.err = err
+	}
+	return n, err
+}
+
+// result returns the error to finish the bar with once the handler is
+// done, given the Content-Length of the request.
+func (c *countingBody) result(length int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case c.err != nil:
+		return c.err
+	case c.eof, length == 0, length > 0 && c.read >= length:
+		return nil
+	default:
+		return ErrAborted // the handler stopped reading
+	}
+}
@@ -0,0 +1,103 @@
+package ravan
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// TestTrackUploads verifies the progress of a body can be queried while
+// the handler reads it.
+func TestTrackUploads(t *testing.T) {
+	reg := NewRegistry()
+	var during Snapshot
+	handler := TrackUploads(reg, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.CopyN(io.Discard, req.Body, 300)
+		during, _ = reg.Snapshot(RequestID(req.Context()))
+		io.Copy(io.Discard, req.Body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPut, "/upload", bytes.NewReader(make([]byte, 1000)))
+	req.Header.Set(RequestIDHeader, "up-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if during.Current != 300 || during.Total != 1000 || during.Label != "up-1" {
+		t.Errorf("snapshot during the upload = %+v; want 300 of 1000 bytes", during)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "up-1" {
+		t.Errorf("response %s = %q; want up-1", RequestIDHeader, got)
+	}
+	if s, ok := reg.Snapshot("up-1"); !ok || s.Status != Succeeded || s.Current != 1000 {
+		t.Errorf("snapshot after the upload = %+v, %v; want a finished upload", s, ok)
+	}
+}
+
+// TestTrackUploadsUnknownLength verifies watchers follow an upload
+// without Content-Length, which has no total to draw.
+func TestTrackUploadsUnknownLength(t *testing.T) {
+	reg := NewRegistry()
+	var during Snapshot
+	handler := TrackUploads(reg, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bar, _ := reg.Get(RequestID(req.Context()))
+		updates, stop := bar.Watch()
+		defer stop()
+
+		io.CopyN(io.Discard, req.Body, 5000)
+		select {
+		case during = <-updates:
+		case <-time.After(time.Second):
+		}
+		io.Copy(io.Discard, req.Body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPut, "/upload", nil)
+	req.Body = io.NopCloser(bytes.NewReader(make([]byte, 8000)))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if during.Current != 5000 || during.Total != 0 || during.Status != Running {
+		t.Errorf("update during the upload = %+v; want 5000 bytes of an unknown total", during)
+	}
+}
+
+func TestTrackUploadsOutcome(t *testing.T) {
+	broken := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+
+	tests := []struct {
+		name   string
+		body   io.Reader
+		read   bool
+		status Status
+	}{
+		{"unread body", strings.NewReader("payload"), false, Aborted},
+		{"broken body", broken, true, Failed},
+		{"no body", nil, false, Succeeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewRegistry()
+			var id string
+			handler := TrackUploads(reg, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				id = RequestID(req.Context())
+				if tt.read {
+					io.Copy(io.Discard, req.Body)
+				}
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", tt.body))
+
+			if len(id) != 16 {
+				t.Errorf("generated request ID %q; want 16 hex digits", id)
+			}
+			if s, _ := reg.Snapshot(id); s.Status != tt.status {
+				t.Errorf("status %s; want %s", s.Status, tt.status)
+			}
+		})
+	}
+}
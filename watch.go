@@ -0,0 +1,105 @@
+package ravan
+
+// watcher is notified of every state the renderer of a bar is given.
+// notify is called with the bar's lock held and must not block.
+type watcher struct {
+	notify func(s Snapshot)
+}
+
+// watchedRenderer hands every update to the renderer of the bar and
+// then to its watchers, so they see exactly what the renderer draws.
+type watchedRenderer struct {
+	r *Ravan
+}
+
+func (w watchedRenderer) Start(s Snapshot) {
+	w.r.out.Start(s)
+	w.r.notify(s)
+}
+
+func (w watchedRenderer) Progress(s Snapshot) {
+	w.r.out.Progress(s)
+	w.r.notify(s)
+}
+
+func (w watchedRenderer) Message(s Snapshot, kind MessageKind, err error, text string) {
+	w.r.out.Message(s, kind, err, text)
+	w.r.notify(s)
+}
+
+func (w watchedRenderer) Finish(s Snapshot) {
+	w.r.out.Finish(s)
+	w.r.notify(s)
+}
+
+// notify hands s to all watchers. r.mu must be held.
+func (r *Ravan) notify(s Snapshot) {
+	for _, w := range r.watchers {
+		w.notify(s)
+	}
+}
+
+// addWatcher registers a watcher and returns a function removing it.
+func (r *Ravan) addWatcher(notify func(s Snapshot)) (remove func()) {
+	w := &watcher{notify: notify}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watchers = append(r.watchers, w)
+	r.nextDraw.Store(0) // the next Add tells the count, even without a total
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i, other := range r.watchers {
+			if other == w {
+				r.watchers = append(r.watchers[:i:i], r.watchers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Watch returns a channel that receives the state of the bar whenever
+// its renderer draws, and a function to stop watching. A bar without a
+// total, which Add doesn't draw, sends its count at most WithMaxFPS
+// times per second instead. The channel keeps
+// only the latest state, so a slow reader skips states but never slows
+// the bar down. It is closed after the final state once the bar is
+// finished, or by stop.
+func (r *Ravan) Watch() (updates <-chan Snapshot, stop func()) {
+	ch := make(chan Snapshot, 1)
+	closed := false // guarded by r.mu like all watcher calls
+
+	send := func(s Snapshot) {
+		if closed {
+			return
+		}
+		select {
+		case <-ch: // replace a state the reader hasn't taken yet
+		default:
+		}
+		ch <- s
+		if s.Status != Running {
+			closed = true
+			close(ch)
+		}
+	}
+	remove := r.addWatcher(send)
+
+	r.mu.Lock()
+	if r.status != Running {
+		send(r.state())
+	}
+	r.mu.Unlock()
+
+	return ch, func() {
+		remove()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if !closed {
+			closed = true
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ravan
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pooulad/ravan/ravantest"
+)
+
+// TestWatch verifies watchers get the states the renderer draws and the
+// channel is closed after the final state.
+func TestWatch(t *testing.T) {
+	r, _ := New(WithTotal(4), WithWriter(io.Discard), WithMaxFPS(0))
+	updates, stop := r.Watch()
+	defer stop()
+
+	r.Add(1)
+	if s := <-updates; s.Current != 1 || s.Fraction != 0.25 {
+		t.Errorf("first update = %+v; want 1 of 4", s)
+	}
+
+	// A slow reader only sees the latest state
+	r.Add(1)
+	r.Add(1)
+	if s := <-updates; s.Current != 3 {
+		t.Errorf("latest update = %+v; want 3 of 4", s)
+	}
+
+	r.Finish(nil)
+	if s := <-updates; s.Status != Succeeded {
+		t.Errorf("final update = %+v; want succeeded", s)
+	}
+	if _, ok := <-updates; ok {
+		t.Error("expected the channel to be closed after the final state")
+	}
+}
+
+// TestWatchWithoutTotal verifies the count of a bar without a total is
+// sent at the frame rate.
+func TestWatchWithoutTotal(t *testing.T) {
+	clock := ravantest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r, _ := New(WithWriter(io.Discard), WithClock(clock), WithMaxFPS(10))
+	updates, stop := r.Watch()
+	defer stop()
+
+	r.Add(1)
+	if s := <-updates; s.Current != 1 {
+		t.Errorf("first update = %+v; want 1", s)
+	}
+	r.Add(1) // inside the same 100ms frame
+	select {
+	case s := <-updates:
+		t.Errorf("unexpected update %+v inside the frame", s)
+	default:
+	}
+
+	clock.Advance(100 * time.Millisecond)
+	r.Add(1)
+	if s := <-updates; s.Current != 3 {
+		t.Errorf("update once the frame is due = %+v; want 3", s)
+	}
+}
+
+func TestWatchStop(t *testing.T) {
+	r, _ := New(WithTotal(4), WithWriter(io.Discard), WithMaxFPS(0))
+	updates, stop := r.Watch()
+	stop()
+	stop() // stopping twice is harmless
+
+	r.Add(1)
+	if _, ok := <-updates; ok {
+		t.Error("expected no updates after stop")
+	}
+	if len(r.watchers) != 0 {
+		t.Errorf("expected the watcher to be removed, got %d", len(r.watchers))
+	}
+}
+
+func TestWatchFinished(t *testing.T) {
+	r, _ := New(WithWriter(io.Discard))
+	r.Finish(ErrAborted)
+
+	updates, _ := r.Watch()
+	if s, ok := <-updates; !ok || s.Status != Aborted {
+		t.Errorf("Watch() of a finished bar = %+v, %v; want the final state", s, ok)
+	}
+	if _, ok := <-updates; ok {
+		t.Error("expected a closed channel")
+	}
+}
@@ -0,0 +1,101 @@
+package ravan
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+//go:embed web/index.html
+var indexHTML []byte
+
+// sseKeepAlive is how often an idle event stream sends a comment so
+// proxies don't close it.
+const sseKeepAlive = 15 * time.Second
+
+// WebHandler serves a page that shows the bars of reg live in a browser:
+//
+//	GET /        the page
+//	GET /events  Server-Sent Events, see below
+//	GET /status  the JSON status of the bars, see Registry.ServeHTTP
+//
+// The event stream starts with a "progress" event for every bar and
+// sends another one with the JSON state of a bar whenever its renderer
+// draws it, so the page follows the same updates as the terminal, or
+// its count changes if it has no total, see Ravan.Watch.
+// A "remove" event tells when a bar left reg. Mount the handler with
+// http.StripPrefix to serve it below a path:
+//
+//	http.Handle("/progress/", http.StripPrefix("/progress", ravan.WebHandler(reg)))
+func WebHandler(reg *Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexHTML)
+	})
+	mux.Handle("GET /status", reg)
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, req *http.Request) {
+		serveEvents(w, req, reg)
+	})
+	return mux
+}
+
+// serveEvents streams the changes of the bars of reg until the client leaves.
+func serveEvents(w http.ResponseWriter, req *http.Request, reg *Registry) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// Watch before listing the bars so no change is missed
+	watch, stop := reg.watch()
+	defer stop()
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	names := reg.Names()
+	for {
+		for _, name := range names {
+			var err error
+			if s, ok := reg.Snapshot(name); ok {
+				err = writeEvent(w, "progress", newJSONStatus(name, s))
+			} else {
+				err = writeEvent(w, "remove", struct {
+					Name string `json:"name"`
+				}{name})
+			}
+			if err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-watch.changed:
+			names = watch.take()
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			names = nil
+		}
+	}
+}
+
+// writeEvent writes a Server-Sent Event with JSON data.
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
@@ -0,0 +1,106 @@
+package ravan
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readEvent reads the next Server-Sent Event, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) (event string, status jsonStatus) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading events: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status); err != nil {
+				t.Fatalf("invalid data %q: %v", line, err)
+			}
+		case line == "" && event != "":
+			return event, status
+		}
+	}
+}
+
+func TestWebHandlerEvents(t *testing.T) {
+	reg := NewRegistry()
+	first, _ := New(WithTotal(10), WithWriter(io.Discard), WithMaxFPS(0))
+	reg.Add("first", first)
+
+	srv := httptest.NewServer(http.StripPrefix("/progress", WebHandler(reg)))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/progress/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	events := bufio.NewReader(resp.Body)
+
+	if event, s := readEvent(t, events); event != "progress" || s.Name != "first" || s.Total != 10 {
+		t.Errorf("initial event %s %+v; want the progress of first", event, s)
+	}
+
+	first.Add(4)
+	if event, s := readEvent(t, events); event != "progress" || s.Current != 4 || s.Fraction != 0.4 {
+		t.Errorf("event %s %+v; want first at 40%%", event, s)
+	}
+
+	second, _ := New(WithWriter(io.Discard))
+	reg.Add("second", second)
+	if event, s := readEvent(t, events); event != "progress" || s.Name != "second" {
+		t.Errorf("event %s %+v; want the new bar", event, s)
+	}
+
+	first.Finish(nil)
+	reg.Remove("second")
+	got := map[string]string{}
+	for len(got) < 2 {
+		event, s := readEvent(t, events)
+		got[s.Name] = event + " " + s.Status
+	}
+	if got["first"] != "progress succeeded" || got["second"] != "remove " {
+		t.Errorf("events %v; want first succeeded and second removed", got)
+	}
+}
+
+func TestWebHandlerPage(t *testing.T) {
+	srv := httptest.NewServer(WebHandler(NewRegistry()))
+	defer srv.Close()
+
+	for path, want := range map[string]string{
+		"/":       `new EventSource("events")`,
+		"/status": "[]",
+	} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("GET %s = %d %q; want it to contain %q", path, resp.StatusCode, body, want)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /missing = %d; want 404", resp.StatusCode)
+	}
+}